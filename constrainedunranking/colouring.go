@@ -0,0 +1,304 @@
+// Package constrainedunranking provides functions to unrank set partitions under constraints lexicographicaly
+//
+// The partitions of [|1,n|] into k blocks that respect the constraints are listed in the
+// order of parallelunranking.UnrankDicho, the partitions that break a constraint being
+// skipped. The counting is a dynamic programming over subsets of [|1,n|], so n must
+// be at most 64 and should stay small.
+package constrainedunranking
+
+import (
+	"math/big"
+	"math/bits"
+	"math/rand"
+	"sort"
+
+	"github.com/AMAURYCU/setpartition_unrank/types"
+)
+
+type state struct {
+	rest, allowed uint64
+	k             int
+}
+
+// ColouringUnranker unranks the partitions of the vertices of a graph into k independent sets,
+// that is the proper colourings with k colours up to a permutation of the colours.
+type ColouringUnranker struct {
+	n, k int
+	// adj[x] holds the vertices that cannot share a block with x
+	adj []uint64
+	// group[x] holds the vertices that must share a block with x, x included
+	group []uint64
+	// reps holds the smallest vertex of every group that can make a block
+	reps uint64
+	memo map[state]*big.Int
+}
+
+/*
+Build the unranker of the partitions of [|1,n|] into k independent sets of the graph
+whose edges are given as pairs of vertices in [|1,n|], or nil when n > 64 or an edge
+has a vertex outside [|1,n|].
+*/
+func NewColouringUnranker(n, k int, edges [][2]int) *ColouringUnranker {
+	if n < 0 || n > 64 || !inRange(n, edges) {
+		return nil
+	}
+	group := make([]uint64, n)
+	for x := range group {
+		group[x] = 1 << uint(x)
+	}
+	return newUnranker(n, k, group, edges)
+}
+
+// inRange tells whether the two elements of every pair lie in [|1,n|].
+func inRange(n int, pairs [][2]int) bool {
+	for _, e := range pairs {
+		if e[0] < 1 || e[0] > n || e[1] < 1 || e[1] > n {
+			return false
+		}
+	}
+	return true
+}
+
+// newUnranker builds the unranker of the graph where the vertices of a same group are contracted.
+func newUnranker(n, k int, group []uint64, edges [][2]int) *ColouringUnranker {
+	c := &ColouringUnranker{n: n, k: k, adj: make([]uint64, n), group: group, memo: make(map[state]*big.Int)}
+	for _, e := range edges {
+		x, y := e[0]-1, e[1]-1
+		for a := group[x]; a != 0; a &= a - 1 {
+			c.adj[bits.TrailingZeros64(a)] |= group[y]
+		}
+		for a := group[y]; a != 0; a &= a - 1 {
+			c.adj[bits.TrailingZeros64(a)] |= group[x]
+		}
+	}
+	for x := 0; x < n; x++ {
+		if bits.TrailingZeros64(group[x]) == x && c.adj[x]&group[x] == 0 {
+			c.reps |= 1 << uint(x)
+		}
+	}
+	return c
+}
+
+// above returns the vertices greater than v.
+func above(v int) uint64 {
+	if v >= 63 {
+		return 0
+	}
+	return ^uint64(0) << uint(v+1)
+}
+
+/*
+Number of ways to end the current block with compatible groups taken in allowed and to split
+rest minus these groups into k independent sets. The smallest vertex of rest always starts the
+next block, which is the chromatic polynomial recursion restricted to the lexicographic order.
+*/
+func (c *ColouringUnranker) ways(rest, allowed uint64, k int) *big.Int {
+	if k == 0 {
+		// every vertex left must join the current block
+		for a := rest; a != 0; a &= a - 1 {
+			x := bits.TrailingZeros64(a)
+			if c.adj[x]&rest != 0 || allowed&c.group[x] == 0 {
+				return big.NewInt(0)
+			}
+		}
+		return big.NewInt(1)
+	}
+	if bits.OnesCount64(rest) < k {
+		return big.NewInt(0)
+	}
+	s := state{rest, allowed, k}
+	if res, ok := c.memo[s]; ok {
+		return res
+	}
+	res := new(big.Int).Set(c.count(rest, k))
+	for a := allowed; a != 0; a &= a - 1 {
+		e := bits.TrailingZeros64(a)
+		res.Add(res, c.ways(rest&^c.group[e], allowed&above(e)&^c.adj[e], k))
+	}
+	c.memo[s] = res
+	return res
+}
+
+// count returns the number of partitions of rest into k independent sets.
+func (c *ColouringUnranker) count(rest uint64, k int) *big.Int {
+	if rest == 0 {
+		if k == 0 {
+			return big.NewInt(1)
+		}
+		return big.NewInt(0)
+	}
+	v := bits.TrailingZeros64(rest)
+	if k == 0 || c.reps&(1<<uint(v)) == 0 {
+		return big.NewInt(0)
+	}
+	rest &^= c.group[v]
+	return c.ways(rest, rest&c.reps&^c.adj[v], k-1)
+}
+
+func (c *ColouringUnranker) all() uint64 {
+	if c.n == 64 {
+		return ^uint64(0)
+	}
+	return 1<<uint(c.n) - 1
+}
+
+// Return the number of partitions of the vertices into k independent sets.
+func (c *ColouringUnranker) Count() *big.Int {
+	return new(big.Int).Set(c.count(c.all(), c.k))
+}
+
+/*
+The blocks are built in the lexicographic order of their sorted list of vertices.
+The list grows one vertex x at a time, x being either the smallest pending vertex,
+that is a vertex of a group already in the block, or the smallest vertex of a new group.
+*/
+type prefix struct {
+	rest, allowed, pending uint64
+	block                  []int
+}
+
+func (c *ColouringUnranker) start(rest uint64) *prefix {
+	v := bits.TrailingZeros64(rest)
+	rest &^= c.group[v]
+	return &prefix{rest: rest, allowed: rest & c.reps &^ c.adj[v], pending: c.group[v] &^ (1 << uint(v)), block: []int{v + 1}}
+}
+
+// children visits the vertices that can extend p, in increasing order, with their number of completions.
+func (c *ColouringUnranker) children(p *prefix, k int, visit func(x int, w *big.Int) bool) {
+	candidates := p.allowed
+	f := -1
+	if p.pending != 0 {
+		f = bits.TrailingZeros64(p.pending)
+		candidates &= 1<<uint(f) - 1
+	}
+	for a := candidates; a != 0; a &= a - 1 {
+		e := bits.TrailingZeros64(a)
+		if !visit(e, c.ways(p.rest&^c.group[e], p.allowed&above(e)&^c.adj[e], k)) {
+			return
+		}
+	}
+	if f >= 0 {
+		visit(f, c.ways(p.rest, p.allowed&above(f), k))
+	}
+}
+
+func (c *ColouringUnranker) extend(p *prefix, x int) {
+	if p.pending&(1<<uint(x)) != 0 {
+		p.pending &^= 1 << uint(x)
+		p.allowed &= above(x)
+	} else {
+		p.pending |= c.group[x] &^ (1 << uint(x))
+		p.rest &^= c.group[x]
+		p.allowed &= above(x) &^ c.adj[x]
+	}
+	p.block = append(p.block, x+1)
+}
+
+//  Unrank graph colouring lexicographicaly.
+/*
+Return the partition of rank rank, 0 <= rank < Count(), among the partitions of the
+vertices into k independent sets, in the order of parallelunranking.UnrankDicho,
+or nil when rank is out of range.
+
+Example usage:
+
+	c := constrainedunranking.NewColouringUnranker(4, 2, [][2]int{{1, 2}, {2, 3}})
+	fmt.Println(c.Unrank(*big.NewInt(1))) // Output: [[1 3 4] [2]]
+*/
+func (c *ColouringUnranker) Unrank(rank big.Int) [][]int {
+	if rank.Sign() < 0 || rank.Cmp(c.Count()) >= 0 {
+		return nil
+	}
+	r := new(big.Int).Set(&rank)
+	res := make([][]int, 0)
+	rest := c.all()
+	for k := c.k; k > 0; k-- {
+		if rest == 0 {
+			return nil
+		}
+		p := c.start(rest)
+		for {
+			if p.pending == 0 {
+				stop := c.count(p.rest, k-1)
+				if r.Cmp(stop) < 0 {
+					break
+				}
+				r.Sub(r, stop)
+			}
+			chosen := -1
+			c.children(p, k-1, func(x int, w *big.Int) bool {
+				if r.Cmp(w) < 0 {
+					chosen = x
+					return false
+				}
+				r.Sub(r, w)
+				return true
+			})
+			if chosen < 0 {
+				return nil
+			}
+			c.extend(p, chosen)
+		}
+		res = append(res, p.block)
+		rest = p.rest
+	}
+	return res
+}
+
+//  Rank graph colouring lexicographicaly.
+/*
+This function is the inverse of Unrank, the blocks can be given in any order.
+The result is nil when partition is not a partition of [|1,n|] into k independent sets.
+*/
+func (c *ColouringUnranker) Rank(partition [][]int) *big.Int {
+	if types.NewPartition(partition).Validate(c.n, c.k) != nil {
+		return nil
+	}
+	for _, block := range partition {
+		var mask uint64
+		for _, x := range block {
+			mask |= 1 << uint(x-1)
+		}
+		for _, x := range block {
+			if c.adj[x-1]&mask != 0 || c.group[x-1]&^mask != 0 {
+				return nil
+			}
+		}
+	}
+	blocks := make([][]int, len(partition))
+	for i, block := range partition {
+		blocks[i] = append([]int{}, block...)
+		sort.Ints(blocks[i])
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i][0] < blocks[j][0] })
+
+	res := big.NewInt(0)
+	rest := c.all()
+	k := c.k
+	for _, block := range blocks {
+		p := c.start(rest)
+		for _, x := range block[1:] {
+			if p.pending == 0 {
+				res.Add(res, c.count(p.rest, k-1))
+			}
+			c.children(p, k-1, func(e int, w *big.Int) bool {
+				if e >= x-1 {
+					return false
+				}
+				res.Add(res, w)
+				return true
+			})
+			c.extend(p, x-1)
+		}
+		rest = p.rest
+		k--
+	}
+	return res
+}
+
+// Pick uniformly at random one partition of the vertices into k independent sets.
+func (c *ColouringUnranker) Random(rg *rand.Rand) [][]int {
+	var r big.Int
+	r.Rand(rg, c.Count())
+	return c.Unrank(r)
+}
@@ -0,0 +1,72 @@
+package constrainedunranking
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/AMAURYCU/setpartition_unrank/internal/bruteforce"
+)
+
+// randomEdges returns each pair of [|1,n|] with probability p.
+func randomEdges(rg *rand.Rand, n int, p float64) [][2]int {
+	res := make([][2]int, 0)
+	for x := 1; x <= n; x++ {
+		for y := x + 1; y <= n; y++ {
+			if rg.Float64() < p {
+				res = append(res, [2]int{x, y})
+			}
+		}
+	}
+	return res
+}
+
+func TestColouring(t *testing.T) {
+	rg := rand.New(rand.NewSource(1))
+	for n := 1; n <= 7; n++ {
+		for k := 1; k <= n; k++ {
+			for _, p := range []float64{0, 0.3, 0.6} {
+				edges := randomEdges(rg, n, p)
+				want := bruteforce.Filtered(n, k, func(rgs []int) bool {
+					for _, e := range edges {
+						if rgs[e[0]-1] == rgs[e[1]-1] {
+							return false
+						}
+					}
+					return true
+				})
+				c := NewColouringUnranker(n, k, edges)
+				bruteforce.Check(t, fmt.Sprint(n, k, edges), c.Count(), c.Unrank, c.Rank, want)
+			}
+		}
+	}
+}
+
+func TestColouringTooLarge(t *testing.T) {
+	if c := NewColouringUnranker(65, 2, nil); c != nil {
+		t.Errorf("NewColouringUnranker(65, 2, nil) is not nil")
+	}
+	if c := NewColouringUnranker(64, 64, nil); c == nil || c.Count().Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("NewColouringUnranker(64, 64, nil) does not have a single partition")
+	}
+}
+
+func TestColouringInvalid(t *testing.T) {
+	for _, edges := range [][][2]int{{{1, 4}}, {{0, 1}}, {{2, 3}, {3, -1}}} {
+		if c := NewColouringUnranker(3, 2, edges); c != nil {
+			t.Errorf("NewColouringUnranker(3, 2, %v) is not nil", edges)
+		}
+	}
+	c := NewColouringUnranker(3, 2, [][2]int{{1, 2}})
+	for _, r := range []*big.Int{big.NewInt(-1), c.Count()} {
+		if p := c.Unrank(*r); p != nil {
+			t.Errorf("Unrank(%v) = %v, want nil", r, p)
+		}
+	}
+	for _, p := range [][][]int{{{1, 2}, {3}}, {{1, 3}, {2}, {}}, {{1, 3}, {}}, {{1, 3}, {2, 3}}, {{1, 2, 3}}, {{1, 3}, {4}}} {
+		if r := c.Rank(p); r != nil {
+			t.Errorf("Rank(%v) = %v, want nil", p, r)
+		}
+	}
+}
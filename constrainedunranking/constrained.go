@@ -0,0 +1,51 @@
+package constrainedunranking
+
+// ConstrainedUnranker unranks the partitions of [|1,n|] into k blocks such that the two elements of
+// every must-link pair share a block and the two elements of every cannot-link pair do not.
+// The elements linked by must-link pairs are contracted into a single vertex of the cannot-link
+// graph, so it works as the ColouringUnranker of the contracted graph while keeping the order
+// of parallelunranking.UnrankDicho on [|1,n|].
+type ConstrainedUnranker struct {
+	ColouringUnranker
+}
+
+/*
+Build the unranker of the partitions of [|1,n|] into k blocks respecting the must-link and
+the cannot-link pairs, or nil when n > 64 or a pair has an element outside [|1,n|]. Count() is 0 when the constraints contradict each other.
+
+Example usage:
+
+	c := constrainedunranking.NewConstrainedUnranker(5, 2, [][2]int{{1, 4}}, [][2]int{{1, 2}})
+	fmt.Println(c.Count(), c.Unrank(*big.NewInt(0))) // Output: 4 [[1 3 4] [2 5]]
+*/
+func NewConstrainedUnranker(n, k int, mustLink, cannotLink [][2]int) *ConstrainedUnranker {
+	if n < 0 || n > 64 || !inRange(n, mustLink) || !inRange(n, cannotLink) {
+		return nil
+	}
+	root := make([]int, n)
+	for x := range root {
+		root[x] = x
+	}
+	var find func(x int) int
+	find = func(x int) int {
+		if root[x] != x {
+			root[x] = find(root[x])
+		}
+		return root[x]
+	}
+	for _, e := range mustLink {
+		a, b := find(e[0]-1), find(e[1]-1)
+		if a != b {
+			root[a] = b
+		}
+	}
+	members := make(map[int]uint64)
+	for x := 0; x < n; x++ {
+		members[find(x)] |= 1 << uint(x)
+	}
+	group := make([]uint64, n)
+	for x := range group {
+		group[x] = members[find(x)]
+	}
+	return &ConstrainedUnranker{*newUnranker(n, k, group, cannotLink)}
+}
@@ -0,0 +1,52 @@
+package constrainedunranking
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/AMAURYCU/setpartition_unrank/internal/bruteforce"
+)
+
+func TestConstrained(t *testing.T) {
+	rg := rand.New(rand.NewSource(2))
+	for n := 1; n <= 7; n++ {
+		for k := 1; k <= n; k++ {
+			for _, p := range [][2]float64{{0, 0}, {0.15, 0}, {0, 0.3}, {0.1, 0.2}, {0.3, 0.3}} {
+				mustLink, cannotLink := randomEdges(rg, n, p[0]), randomEdges(rg, n, p[1])
+				want := bruteforce.Filtered(n, k, func(rgs []int) bool {
+					for _, e := range mustLink {
+						if rgs[e[0]-1] != rgs[e[1]-1] {
+							return false
+						}
+					}
+					for _, e := range cannotLink {
+						if rgs[e[0]-1] == rgs[e[1]-1] {
+							return false
+						}
+					}
+					return true
+				})
+				c := NewConstrainedUnranker(n, k, mustLink, cannotLink)
+				bruteforce.Check(t, fmt.Sprint(n, k, mustLink, cannotLink), c.Count(), c.Unrank, c.Rank, want)
+			}
+		}
+	}
+}
+
+func TestConstrainedTooLarge(t *testing.T) {
+	if c := NewConstrainedUnranker(65, 2, [][2]int{{1, 65}}, nil); c != nil {
+		t.Errorf("NewConstrainedUnranker(65, 2, ...) is not nil")
+	}
+}
+
+func TestConstrainedInvalid(t *testing.T) {
+	for _, pairs := range [][][2]int{{{1, 4}}, {{0, 1}}, {{4, 4}}} {
+		if c := NewConstrainedUnranker(3, 2, pairs, nil); c != nil {
+			t.Errorf("NewConstrainedUnranker(3, 2, %v, nil) is not nil", pairs)
+		}
+		if c := NewConstrainedUnranker(3, 2, nil, pairs); c != nil {
+			t.Errorf("NewConstrainedUnranker(3, 2, nil, %v) is not nil", pairs)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+// Package grayunranking provides a Gray code for the set partitions of [|1,n|] into k blocks
+//
+// Two consecutive set partitions of the code differ by the move of a single element from one block to
+// another. The code is built on the recursion S(n,k) = S(n-1,k-1) + kS(n-1,k) : the partitions where n
+// is alone come first, then the element n visits every block while the code of the partitions of
+// [|1,n-1|] into k blocks is run k times, alternately forward and backward.
+//
+// A partition of the code is written as a label vector a_1...a_n, a_x in [|1,k|] being the label of the block
+// holding x. The labels are not sorted : they are chosen so that only one letter changes between
+// two consecutive vectors.
+package grayunranking
+
+import (
+	"math/big"
+
+	"github.com/AMAURYCU/setpartition_unrank/types"
+)
+
+/*
+ends describes the code of the partitions of [|1,n|] into k blocks : its first and last label vectors,
+the relabelling sigma of the copies of the code of [|1,n-1|] and the labels c[j] taken by n in the copy j.
+*/
+type ends struct {
+	first, last []int
+	sigma, c    []int
+}
+
+// Gray is the Gray code of the set partitions of [|1,n|] into k blocks.
+type Gray struct {
+	n, k int
+	// S[j][i] = S(i,j), as returned by types.StirlingColumns
+	S    [][]big.Int
+	ends map[[2]int]*ends
+}
+
+// NewGray builds the Gray code of the set partitions of [|1,n|] into k blocks, 1 <= k <= n.
+func NewGray(n, k int) *Gray {
+	return &Gray{n: n, k: k, S: types.StirlingColumns(n, k), ends: make(map[[2]int]*ends)}
+}
+
+// Return the number of partitions in the code, S(n,k).
+func (g *Gray) Count() *big.Int {
+	return new(big.Int).Set(&g.S[g.k][g.n])
+}
+
+func (g *Gray) getEnds(n, k int) *ends {
+	if e, ok := g.ends[[2]int{n, k}]; ok {
+		return e
+	}
+	e := &ends{}
+	if k == 1 || k == n {
+		t := make([]int, n)
+		for x := range t {
+			t[x] = 1
+			if k == n {
+				t[x] = x + 1
+			}
+		}
+		e.first, e.last = t, t
+		g.ends[[2]int{n, k}] = e
+		return e
+	}
+	m := n - 1
+	x := g.getEnds(m, k-1)
+	b := g.getEnds(m, k)
+	// the copy 0 starts with the element z alone in the block k, one move away from the last vector of x
+	z := m - k + 1
+	y := b.last
+	if k == m || k%2 == 0 {
+		z = m
+	}
+	if k%2 == 0 {
+		y = b.first
+	}
+	e.sigma = make([]int, k+1)
+	for i := 0; i < m; i++ {
+		if i == z-1 {
+			e.sigma[y[i]] = k
+		} else {
+			e.sigma[y[i]] = x.last[i]
+		}
+	}
+	last := e.sigma[b.first[0]]
+	e.c = append(make([]int, 0, k), k)
+	for v := 1; v < k; v++ {
+		if v != last {
+			e.c = append(e.c, v)
+		}
+	}
+	e.c = append(e.c, last)
+	e.first = append(append(make([]int, 0, n), x.first...), k)
+	e.last = make([]int, 0, n)
+	for _, v := range b.first {
+		e.last = append(e.last, e.sigma[v])
+	}
+	e.last = append(e.last, last)
+	g.ends[[2]int{n, k}] = e
+	return e
+}
+
+// forward tells whether the copy j of the code of [|1,n-1|] into k blocks is run forward.
+func forward(j, k int) bool {
+	if k%2 == 0 {
+		return j%2 == 0
+	}
+	return j%2 == 1
+}
+
+// Return the label vector of rank rank.
+func (g *Gray) unrank(n, k int, rank *big.Int) []int {
+	if k == 1 || k == n {
+		return append(make([]int, 0, g.n), g.getEnds(n, k).first...)
+	}
+	m := n - 1
+	if rank.Cmp(&g.S[k-1][m]) < 0 {
+		return append(g.unrank(m, k-1, rank), k)
+	}
+	e := g.getEnds(n, k)
+	r := new(big.Int).Sub(rank, &g.S[k-1][m])
+	q, pos := new(big.Int).QuoRem(r, &g.S[k][m], new(big.Int))
+	j := int(q.Int64())
+	if !forward(j, k) {
+		pos.Sub(&g.S[k][m], pos.Add(pos, big.NewInt(1)))
+	}
+	t := g.unrank(m, k, pos)
+	for x := range t {
+		t[x] = e.sigma[t[x]]
+	}
+	return append(t, e.c[j])
+}
+
+//  Unrank set partition in the Gray code order.
+/*
+Return the partition of rank rank, 0 <= rank < Count(), sorted like parallelunranking.UnrankDicho does,
+or nil when rank is out of range.
+
+Example usage:
+
+	g := grayunranking.NewGray(4, 2)
+	fmt.Println(g.Unrank(*big.NewInt(1))) // Output: [[1 2] [3 4]]
+*/
+func (g *Gray) Unrank(rank big.Int) [][]int {
+	labels := g.UnrankLabels(rank)
+	if labels == nil {
+		return nil
+	}
+	return toBlocks(labels)
+}
+
+// UnrankLabels returns the label vector of rank rank, 0 <= rank < Count(), nil when rank is out of range.
+func (g *Gray) UnrankLabels(rank big.Int) []int {
+	if !g.inRange(&rank) {
+		return nil
+	}
+	return g.unrank(g.n, g.k, &rank)
+}
+
+func (g *Gray) inRange(rank *big.Int) bool {
+	return rank.Sign() >= 0 && rank.Cmp(&g.S[g.k][g.n]) < 0
+}
+
+func toBlocks(labels []int) [][]int {
+	l := make([]int, len(labels))
+	for x, v := range labels {
+		l[x] = v - 1
+	}
+	return types.NewPartitionFromLabels(l).Canonical().Blocks()
+}
+
+// rank returns the rank and the label vector of the partition of [|1,n|] into k blocks given by the block ids p.
+func (g *Gray) rank(p, size, first []int, n, k int) (*big.Int, []int) {
+	if k == 1 || k == n {
+		return big.NewInt(0), append(make([]int, 0, g.n), g.getEnds(n, k).first...)
+	}
+	m := n - 1
+	b := p[m]
+	size[b]--
+	if size[b] == 0 {
+		r, t := g.rank(p, size, first, m, k-1)
+		return r, append(t, k)
+	}
+	e := g.getEnds(n, k)
+	pos, t := g.rank(p, size, first, m, k)
+	for x := range t {
+		t[x] = e.sigma[t[x]]
+	}
+	label := t[first[b]]
+	j := 0
+	for e.c[j] != label {
+		j++
+	}
+	if !forward(j, k) {
+		pos.Sub(&g.S[k][m], pos.Add(pos, big.NewInt(1)))
+	}
+	res := new(big.Int).Mul(big.NewInt(int64(j)), &g.S[k][m])
+	res.Add(res, &g.S[k-1][m])
+	return res.Add(res, pos), append(t, label)
+}
+
+//  Rank set partition in the Gray code order.
+/*
+This function is the inverse of Unrank, the blocks can be given in any order.
+The result is nil when partition is not a set partition of [|1,n|] into k blocks.
+*/
+func (g *Gray) Rank(partition [][]int) *big.Int {
+	p := types.NewPartition(partition)
+	if p.Validate(g.n, g.k) != nil {
+		return nil
+	}
+	first := make([]int, p.K())
+	size := make([]int, p.K())
+	labels := p.Labels()
+	for x := len(labels) - 1; x >= 0; x-- {
+		first[labels[x]] = x
+		size[labels[x]]++
+	}
+	r, _ := g.rank(labels, size, first, g.n, g.k)
+	return r
+}
+
+/*
+Run the part of the code of [|1,n|] into k blocks seen through relabel, skipping its skip first vectors.
+The letters of labels above n are set by the caller.
+*/
+func (g *Gray) run(n, k int, fwd bool, relabel []int, skip *big.Int, labels []int, emit func() bool) bool {
+	if k == 1 || k == n {
+		if skip.Sign() > 0 {
+			skip.Sub(skip, big.NewInt(1))
+			return true
+		}
+		for x, v := range g.getEnds(n, k).first {
+			labels[x] = relabel[v]
+		}
+		return emit()
+	}
+	m := n - 1
+	e := g.getEnds(n, k)
+	sub := make([]int, k+1)
+	for v := 1; v <= k; v++ {
+		sub[v] = relabel[e.sigma[v]]
+	}
+	part := func(i int) bool {
+		if i == 0 {
+			if skip.Cmp(&g.S[k-1][m]) >= 0 {
+				skip.Sub(skip, &g.S[k-1][m])
+				return true
+			}
+			labels[m] = relabel[k]
+			return g.run(m, k-1, fwd, relabel, skip, labels, emit)
+		}
+		j := i - 1
+		if skip.Cmp(&g.S[k][m]) >= 0 {
+			skip.Sub(skip, &g.S[k][m])
+			return true
+		}
+		labels[m] = relabel[e.c[j]]
+		return g.run(m, k, forward(j, k) == fwd, sub, skip, labels, emit)
+	}
+	for i := 0; i <= k; i++ {
+		next := i
+		if !fwd {
+			next = k - i
+		}
+		if !part(next) {
+			return false
+		}
+	}
+	return true
+}
+
+//  Enumerate set partitions in the Gray code order.
+/*
+Call visit on the label vectors of the code from the rank from, until visit returns false or the
+code ends, nothing being visited when from is out of range. labels must not be modified, it is updated in place. moved is the element that moved
+from the previous vector, 0 for the first one.
+
+Example usage:
+
+	g := grayunranking.NewGray(4, 2)
+	g.Enumerate(*big.NewInt(0), func(labels []int, moved int) bool {
+		fmt.Println(labels, moved)
+		return true
+	})
+*/
+func (g *Gray) Enumerate(from big.Int, visit func(labels []int, moved int) bool) {
+	if !g.inRange(&from) {
+		return
+	}
+	labels := make([]int, g.n)
+	previous := make([]int, g.n)
+	relabel := make([]int, g.k+1)
+	for v := range relabel {
+		relabel[v] = v
+	}
+	started := false
+	g.run(g.n, g.k, true, relabel, new(big.Int).Set(&from), labels, func() bool {
+		moved := 0
+		if started {
+			for x := range labels {
+				if labels[x] != previous[x] {
+					moved = x + 1
+					previous[x] = labels[x]
+				}
+			}
+		} else {
+			copy(previous, labels)
+			started = true
+		}
+		return visit(labels, moved)
+	})
+}
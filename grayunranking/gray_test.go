@@ -0,0 +1,101 @@
+package grayunranking
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/AMAURYCU/setpartition_unrank/parallelunranking"
+	"github.com/AMAURYCU/setpartition_unrank/types"
+)
+
+func TestGray(t *testing.T) {
+	for n := 1; n <= 7; n++ {
+		for k := 1; k <= n; k++ {
+			// every partition of [|1,n|] into k blocks must be met once
+			want := make(map[string]bool)
+			parallelunranking.Enumerate(n, k, func(rgs []int) bool {
+				want[fmt.Sprint(types.RGSToBlocks(rgs))] = true
+				return true
+			})
+			g := NewGray(n, k)
+			if count := g.Count(); count.Cmp(big.NewInt(int64(len(want)))) != 0 {
+				t.Fatalf("NewGray(%d, %d).Count() = %v, want %d", n, k, count, len(want))
+			}
+			var previous []int
+			for i := 0; i < len(want); i++ {
+				rank := *big.NewInt(int64(i))
+				labels := g.UnrankLabels(rank)
+				blocks := g.Unrank(rank)
+				if !want[fmt.Sprint(blocks)] {
+					t.Fatalf("NewGray(%d, %d).Unrank(%d) = %v, met twice or not a partition into %d blocks", n, k, i, blocks, k)
+				}
+				delete(want, fmt.Sprint(blocks))
+				if previous != nil {
+					moves := 0
+					for x := range labels {
+						if labels[x] != previous[x] {
+							moves++
+						}
+					}
+					if moves != 1 {
+						t.Fatalf("NewGray(%d, %d): %v then %v move %d elements", n, k, previous, labels, moves)
+					}
+				}
+				previous = labels
+				if r := g.Rank(blocks); r.Cmp(&rank) != 0 {
+					t.Fatalf("NewGray(%d, %d).Rank(%v) = %v, want %d", n, k, blocks, r, i)
+				}
+			}
+		}
+	}
+}
+
+func TestEnumerate(t *testing.T) {
+	for n := 1; n <= 7; n++ {
+		for k := 1; k <= n; k++ {
+			g := NewGray(n, k)
+			count := int(g.Count().Int64())
+			for _, from := range []int{0, count / 3, count - 1} {
+				i := from
+				g.Enumerate(*big.NewInt(int64(from)), func(labels []int, moved int) bool {
+					want := g.UnrankLabels(*big.NewInt(int64(i)))
+					if fmt.Sprint(labels) != fmt.Sprint(want) {
+						t.Fatalf("NewGray(%d, %d).Enumerate(%d) gives %v at %d, want %v", n, k, from, labels, i, want)
+					}
+					if i > from {
+						before := g.UnrankLabels(*big.NewInt(int64(i - 1)))
+						if moved < 1 || before[moved-1] == labels[moved-1] {
+							t.Fatalf("NewGray(%d, %d).Enumerate(%d) moves %d from %v to %v", n, k, from, moved, before, labels)
+						}
+					} else if moved != 0 {
+						t.Fatalf("NewGray(%d, %d).Enumerate(%d) moves %d first", n, k, from, moved)
+					}
+					i++
+					return true
+				})
+				if i != count {
+					t.Fatalf("NewGray(%d, %d).Enumerate(%d) stops at %d, want %d", n, k, from, i, count)
+				}
+			}
+		}
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	g := NewGray(4, 2)
+	for _, r := range []*big.Int{big.NewInt(-1), g.Count()} {
+		if p := g.Unrank(*r); p != nil {
+			t.Errorf("Unrank(%v) = %v, want nil", r, p)
+		}
+		g.Enumerate(*r, func(labels []int, moved int) bool {
+			t.Fatalf("Enumerate(%v) visits %v", r, labels)
+			return false
+		})
+	}
+	for _, p := range [][][]int{{{1, 2}, {2, 3, 4}}, {{1, 2}, {3}}, {{1, 2, 3, 4}}, {{1}, {2}, {3, 4}}, {{1, 2}, {3, 4}, {}}} {
+		if r := g.Rank(p); r != nil {
+			t.Errorf("Rank(%v) = %v, want nil", p, r)
+		}
+	}
+}
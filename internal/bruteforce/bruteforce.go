@@ -0,0 +1,49 @@
+// Package bruteforce holds the brute-force checks shared by the tests of the unranking packages.
+package bruteforce
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/AMAURYCU/setpartition_unrank/parallelunranking"
+	"github.com/AMAURYCU/setpartition_unrank/types"
+)
+
+//  Filtered returns the partitions of [|1,n|] into k blocks accepted by keep, in the order of UnrankDicho.
+/*
+Example usage:
+	want := bruteforce.Filtered(5, 2, func(rgs []int) bool { return rgs[0] != rgs[1] })
+*/
+func Filtered(n, k int, keep func(rgs []int) bool) [][][]int {
+	res := make([][][]int, 0)
+	parallelunranking.Enumerate(n, k, func(rgs []int) bool {
+		if keep(rgs) {
+			res = append(res, types.RGSToBlocks(rgs))
+		}
+		return true
+	})
+	return res
+}
+
+//  Check compares an unranking scheme with the brute-force list want:
+//  count must be its length, unrank(i) its i-th element and rank the inverse of unrank.
+/*
+Example usage:
+	bruteforce.Check(t, "colouring", c.Count(), c.Unrank, c.Rank, want)
+*/
+func Check(t testing.TB, name string, count *big.Int, unrank func(big.Int) [][]int, rank func([][]int) *big.Int, want [][][]int) {
+	t.Helper()
+	if count == nil || count.Cmp(big.NewInt(int64(len(want)))) != 0 {
+		t.Fatalf("%s: count = %v, want %d", name, count, len(want))
+	}
+	for i, p := range want {
+		got := unrank(*big.NewInt(int64(i)))
+		if fmt.Sprint(got) != fmt.Sprint(p) {
+			t.Fatalf("%s: unrank(%d) = %v, want %v", name, i, got, p)
+		}
+		if r := rank(got); r == nil || r.Cmp(big.NewInt(int64(i))) != 0 {
+			t.Fatalf("%s: rank(%v) = %v, want %d", name, got, r, i)
+		}
+	}
+}
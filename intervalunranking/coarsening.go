@@ -0,0 +1,187 @@
+package intervalunranking
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/AMAURYCU/setpartition_unrank/types"
+)
+
+/*
+The coarsenings of pi into k blocks are the set partitions of the blocks of pi into k parts,
+there are S(m, k) of them when pi has m blocks. A block is built in the lexicographic order of its
+sorted list of elements, growing one element x at a time, x being either the smallest pending
+element, that is an element of a block of pi already taken, or the smallest element of a new block of pi.
+*/
+type coarsening struct {
+	groups    [][]int
+	left      []int
+	columns   [][]big.Int
+	binomials *types.BinomialCache
+}
+
+// Return the number of coarsenings of pi into exactly k blocks.
+func CountCoarsenings(pi [][]int, k int) *big.Int {
+	m := len(sortBlocks(pi))
+	if k < 0 || k > m {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Set(&types.StirlingColumns(m, k)[k][m])
+}
+
+func newCoarsening(pi [][]int, k int) *coarsening {
+	groups := sortBlocks(pi)
+	left := make([]int, len(groups))
+	for i := range left {
+		left[i] = i
+	}
+	return &coarsening{groups: groups, left: left, columns: types.StirlingColumns(len(groups), k), binomials: types.NewBinomialCache(types.DefaultBinomialCacheWords)}
+}
+
+// block is the state of the block being built.
+type block struct {
+	elements []int
+	pending  []int
+	taken    []int
+	// the groups that can still join the block
+	allowed []int
+	r       int
+}
+
+func (c *coarsening) start() *block {
+	g := c.left[0]
+	return &block{
+		elements: []int{c.groups[g][0]},
+		pending:  append([]int{}, c.groups[g][1:]...),
+		taken:    []int{g},
+		allowed:  append([]int{}, c.left[1:]...),
+		r:        len(c.left) - 1,
+	}
+}
+
+// before returns the number of allowed groups whose smallest element is below the smallest pending element.
+func (c *coarsening) before(b *block) int {
+	if len(b.pending) == 0 {
+		return len(b.allowed)
+	}
+	return sort.Search(len(b.allowed), func(i int) bool { return c.groups[b.allowed[i]][0] > b.pending[0] })
+}
+
+// add extends the block with the pending element when t is 0 or with the t-th allowed group.
+func (c *coarsening) add(b *block, t int) {
+	if t == 0 {
+		x := b.pending[0]
+		b.pending = b.pending[1:]
+		b.elements = append(b.elements, x)
+		b.allowed = b.allowed[c.before(&block{pending: []int{x}, allowed: b.allowed}):]
+		return
+	}
+	g := b.allowed[t-1]
+	b.elements = append(b.elements, c.groups[g][0])
+	b.pending = append(b.pending, c.groups[g][1:]...)
+	sort.Ints(b.pending)
+	b.taken = append(b.taken, g)
+	b.allowed = b.allowed[t:]
+	b.r--
+}
+
+// close removes the groups of the block and returns its elements.
+func (c *coarsening) close(b *block) []int {
+	taken := make(map[int]bool)
+	for _, g := range b.taken {
+		taken[g] = true
+	}
+	left := make([]int, 0, len(c.left))
+	for _, g := range c.left {
+		if !taken[g] {
+			left = append(left, g)
+		}
+	}
+	c.left = left
+	return b.elements
+}
+
+//  Unrank the coarsenings of a set partition lexicographicaly.
+/*
+This function takes 3 arguments as parameters :
+- pi : [][]int, a set partition of [|1,n|], blocks in any order.
+- k : int, the number of desired blocks in the result.
+- rank : big.Int, the rank of the desired coarsening, 0 <= rank < CountCoarsenings(pi, k).
+
+Example usage:
+
+	result := intervalunranking.UnrankCoarsening([][]int{{1, 4}, {2}, {3, 5}}, 2, *big.NewInt(1))
+	fmt.Println(result) // Output: [[1 3 4 5] [2]]
+*/
+func UnrankCoarsening(pi [][]int, k int, rank big.Int) [][]int {
+	c := newCoarsening(pi, k)
+	if k < 1 || k > len(c.groups) || rank.Cmp(&c.columns[k][len(c.groups)]) >= 0 {
+		return nil
+	}
+	r := new(big.Int).Set(&rank)
+	res := make([][]int, 0, k)
+	for j := k; j > 0; j-- {
+		column := c.columns[j-1]
+		b := c.start()
+		for {
+			if len(b.pending) == 0 {
+				if r.Cmp(&column[b.r]) < 0 {
+					break
+				}
+				r.Sub(r, &column[b.r])
+			}
+			a := len(b.allowed)
+			total := types.Tail(column, a, b.r, 0, 0, c.binomials)
+			tmax := c.before(b)
+			limit := new(big.Int).Sub(total, r)
+			t := types.Search(1, tmax, limit, func(i int) *big.Int { return types.Tail(column, a-i, b.r, 0, 0, c.binomials) })
+			if tmax == 0 || types.Tail(column, a-t, b.r, 0, 0, c.binomials).Cmp(limit) >= 0 {
+				// the pending element comes after every allowed group below it
+				r.Sub(r, total.Sub(total, types.Tail(column, a-tmax, b.r, 0, 0, c.binomials)))
+				c.add(b, 0)
+				continue
+			}
+			r.Sub(r, total.Sub(total, types.Tail(column, a-t+1, b.r, 0, 0, c.binomials)))
+			c.add(b, t)
+		}
+		res = append(res, c.close(b))
+	}
+	return res
+}
+
+//  Rank the coarsenings of a set partition lexicographicaly.
+/*
+This function is the inverse of UnrankCoarsening, the result is nil when partition
+is not a coarsening of pi into k blocks.
+*/
+func RankCoarsening(pi [][]int, k int, partition [][]int) *big.Int {
+	if !between(pi, partition, k, false) {
+		return nil
+	}
+	c := newCoarsening(pi, k)
+	res := big.NewInt(0)
+	j := k
+	for _, target := range sortBlocks(partition) {
+		column := c.columns[j-1]
+		b := c.start()
+		for _, x := range target[1:] {
+			if len(b.pending) == 0 {
+				res.Add(res, &column[b.r])
+			}
+			a := len(b.allowed)
+			t := 0
+			if len(b.pending) > 0 && b.pending[0] == x {
+				res.Add(res, types.Tail(column, a, b.r, 0, 0, c.binomials))
+				res.Sub(res, types.Tail(column, a-c.before(b), b.r, 0, 0, c.binomials))
+			} else {
+				t = sort.Search(a, func(i int) bool { return c.groups[b.allowed[i]][0] >= x }) + 1
+				res.Add(res, types.Tail(column, a, b.r, 0, 0, c.binomials))
+				res.Sub(res, types.Tail(column, a-t+1, b.r, 0, 0, c.binomials))
+			}
+			c.add(b, t)
+		}
+		c.close(b)
+		j--
+	}
+	return res
+}
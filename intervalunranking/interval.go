@@ -0,0 +1,79 @@
+// Package intervalunranking provides functions to unrank the set partitions of an interval of the partition lattice
+//
+// For a fixed set partition pi of [|1,n|], the refinements of pi are the set partitions whose
+// blocks are all included in a block of pi, and the coarsenings of pi are the set partitions whose
+// blocks are all unions of blocks of pi. Both are listed with exactly k blocks in the order of
+// parallelunranking.UnrankDicho restricted to the interval.
+package intervalunranking
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/AMAURYCU/setpartition_unrank/types"
+)
+
+/*
+Unrank one block among s candidates, the first candidate being always taken,
+when column[m] counts the ways to split the rest with m candidates left.
+Return the positions of the block in the candidates and remove its rank from r.
+*/
+func unrankBlock(column []big.Int, s int, r *big.Int, binomials *types.BinomialCache) []int {
+	positions := []int{0}
+	d, R := 1, s-1
+	for r.Cmp(&column[R]) >= 0 {
+		r.Sub(r, &column[R])
+		total := types.Tail(column, s-d, R, 0, 0, binomials)
+		limit := new(big.Int).Sub(total, r)
+		e := types.Search(d+1, s, limit, func(i int) *big.Int { return types.Tail(column, s-i, R, 0, 0, binomials) })
+		r.Sub(r, total.Sub(total, types.Tail(column, s-e+1, R, 0, 0, binomials)))
+		positions = append(positions, e-1)
+		d, R = e, R-1
+	}
+	return positions
+}
+
+// rankBlock is the inverse of unrankBlock.
+func rankBlock(column []big.Int, s int, positions []int, binomials *types.BinomialCache) *big.Int {
+	res := big.NewInt(0)
+	d, R := 1, s-1
+	for _, p := range positions[1:] {
+		e := p + 1
+		res.Add(res, &column[R])
+		res.Add(res, types.Tail(column, s-d, R, 0, 0, binomials))
+		res.Sub(res, types.Tail(column, s-e+1, R, 0, 0, binomials))
+		d, R = e, R-1
+	}
+	return res
+}
+
+// sortBlocks returns a copy of the blocks, each one sorted, sorted by their smallest element.
+func sortBlocks(partition [][]int) [][]int {
+	blocks := make([][]int, 0, len(partition))
+	for _, block := range partition {
+		if len(block) > 0 {
+			b := append([]int{}, block...)
+			sort.Ints(b)
+			blocks = append(blocks, b)
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i][0] < blocks[j][0] })
+	return blocks
+}
+
+// between tells whether partition is a partition into k blocks of the set partitioned by pi,
+// refining pi when fine is true and coarsening it otherwise.
+func between(pi, partition [][]int, k int, fine bool) bool {
+	p := types.NewPartition(sortBlocks(pi))
+	if p.Validate(p.N(), p.K()) != nil {
+		return false
+	}
+	q := types.NewPartition(partition)
+	if q.Validate(p.N(), k) != nil {
+		return false
+	}
+	if fine {
+		return q.Refines(p)
+	}
+	return p.Refines(q)
+}
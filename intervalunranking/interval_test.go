@@ -0,0 +1,94 @@
+package intervalunranking
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/AMAURYCU/setpartition_unrank/internal/bruteforce"
+	"github.com/AMAURYCU/setpartition_unrank/parallelunranking"
+	"github.com/AMAURYCU/setpartition_unrank/types"
+)
+
+// sameLabel tells whether all the elements of each block of by have the same label in rgs.
+func sameLabel(by, rgs []int) bool {
+	label := make(map[int]int)
+	for x, b := range by {
+		if l, ok := label[b]; ok && l != rgs[x] {
+			return false
+		}
+		label[b] = rgs[x]
+	}
+	return true
+}
+
+// partitions visits the partitions of [|1,n|] with their restricted growth string.
+func partitions(n int, visit func(rgs []int)) {
+	for k := 1; k <= n; k++ {
+		parallelunranking.Enumerate(n, k, func(rgs []int) bool {
+			visit(append([]int{}, rgs...))
+			return true
+		})
+	}
+}
+
+func TestRefinements(t *testing.T) {
+	for n := 1; n <= 6; n++ {
+		partitions(n, func(labels []int) {
+			pi := types.RGSToBlocks(labels)
+			for k := 1; k <= n; k++ {
+				// the blocks of a refinement never meet two blocks of pi
+				want := bruteforce.Filtered(n, k, func(rgs []int) bool { return sameLabel(rgs, labels) })
+				bruteforce.Check(t, fmt.Sprintf("refinements of %v into %d blocks", pi, k), CountRefinements(pi, k),
+					func(r big.Int) [][]int { return UnrankRefinement(pi, k, r) },
+					func(p [][]int) *big.Int { return RankRefinement(pi, k, p) }, want)
+			}
+		})
+	}
+}
+
+func TestCoarsenings(t *testing.T) {
+	for n := 1; n <= 6; n++ {
+		partitions(n, func(labels []int) {
+			pi := types.RGSToBlocks(labels)
+			for k := 1; k <= n; k++ {
+				// the blocks of pi are never split by a coarsening
+				want := bruteforce.Filtered(n, k, func(rgs []int) bool { return sameLabel(labels, rgs) })
+				bruteforce.Check(t, fmt.Sprintf("coarsenings of %v into %d blocks", pi, k), CountCoarsenings(pi, k),
+					func(r big.Int) [][]int { return UnrankCoarsening(pi, k, r) },
+					func(p [][]int) *big.Int { return RankCoarsening(pi, k, p) }, want)
+			}
+		})
+	}
+}
+
+func TestRankInvalid(t *testing.T) {
+	pi := [][]int{{1, 4}, {2}, {3, 5}}
+	for _, c := range []struct {
+		k int
+		p [][]int
+	}{
+		{2, [][]int{{1, 2}, {3, 4, 5}}},
+		{2, [][]int{{1, 4}, {2, 3}}},
+		{2, [][]int{{1, 2, 4}, {3, 5}, {}}},
+		{3, [][]int{{1, 2, 4}, {3, 5}}},
+		{2, [][]int{{1, 2, 4}, {3, 5, 6}}},
+	} {
+		if r := RankCoarsening(pi, c.k, c.p); r != nil {
+			t.Errorf("RankCoarsening(%v, %d, %v) = %v, want nil", pi, c.k, c.p, r)
+		}
+	}
+	for _, c := range []struct {
+		k int
+		p [][]int
+	}{
+		{3, [][]int{{1, 2}, {4}, {3, 5}}},
+		{4, [][]int{{1}, {4}, {2, 3}, {5}}},
+		{2, [][]int{{1, 4}, {2}, {3, 5}}},
+		{4, [][]int{{1}, {4}, {2}, {3}}},
+	} {
+		if r := RankRefinement(pi, c.k, c.p); r != nil {
+			t.Errorf("RankRefinement(%v, %d, %v) = %v, want nil", pi, c.k, c.p, r)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package intervalunranking
+
+import (
+	"math/big"
+
+	"github.com/AMAURYCU/setpartition_unrank/types"
+)
+
+/*
+The refinements of pi into k blocks are counted by the coefficient of x^k in the product,
+over the blocks B of pi, of the polynomials sum over j of S(|B|, j) x^j, that is the
+convolution of the stirling columns.
+*/
+type refinements struct {
+	blocks  [][]int
+	columns [][]big.Int
+	// product of the polynomials of the non empty blocks, truncated at degree k
+	product   []big.Int
+	binomials *types.BinomialCache
+}
+
+func newRefinements(pi [][]int, k int) *refinements {
+	blocks := sortBlocks(pi)
+	size := 0
+	for _, block := range blocks {
+		if len(block) > size {
+			size = len(block)
+		}
+	}
+	res := &refinements{blocks: blocks, columns: types.StirlingColumns(size, k), binomials: types.NewBinomialCache(types.DefaultBinomialCacheWords)}
+	res.product = make([]big.Int, k+1)
+	res.product[0].SetInt64(1)
+	for _, block := range blocks {
+		res.product = res.multiply(res.product, len(block), k)
+	}
+	return res
+}
+
+// multiply returns p times the polynomial of a block of size s, truncated at degree k.
+func (ref *refinements) multiply(p []big.Int, s, k int) []big.Int {
+	res := make([]big.Int, k+1)
+	for i := range p {
+		for j := 0; j <= s && i+j <= k && j < len(ref.columns); j++ {
+			res[i+j].Add(&res[i+j], new(big.Int).Mul(&p[i], &ref.columns[j][s]))
+		}
+	}
+	return res
+}
+
+// divide returns p divided by the polynomial of a block of size s >= 1, truncated at degree k-1.
+func (ref *refinements) divide(p []big.Int, s, k int) []big.Int {
+	// the polynomial is x times a series starting with S(s, 1) = 1
+	res := make([]big.Int, k)
+	for i := 0; i < k; i++ {
+		res[i].Set(&p[i+1])
+		for l := 1; l <= i && l+1 <= s && l+1 < len(ref.columns); l++ {
+			res[i].Sub(&res[i], new(big.Int).Mul(&ref.columns[l+1][s], &res[i-l]))
+		}
+	}
+	return res
+}
+
+// Return the number of refinements of pi into exactly k blocks.
+func CountRefinements(pi [][]int, k int) *big.Int {
+	if k < 0 {
+		return big.NewInt(0)
+	}
+	ref := newRefinements(pi, k)
+	return new(big.Int).Set(&ref.product[k])
+}
+
+/*
+The next block is taken in the block b of pi holding the smallest element left.
+column[m] is the number of ways to refine what is left into k-1 blocks when m elements of b are left.
+*/
+func (ref *refinements) column(b, k int) []big.Int {
+	s := len(ref.blocks[b])
+	others := ref.divide(ref.product, s, k)
+	column := make([]big.Int, s+1)
+	for m := 0; m <= s; m++ {
+		for j := 0; j <= m && j <= k-1 && j < len(ref.columns); j++ {
+			column[m].Add(&column[m], new(big.Int).Mul(&ref.columns[j][m], &others[k-1-j]))
+		}
+	}
+	return column
+}
+
+// remove takes the block at the given positions out of the block b of pi and updates the product.
+func (ref *refinements) remove(b, k int, positions []int) []int {
+	s := len(ref.blocks[b])
+	others := ref.divide(ref.product, s, k)
+	block := make([]int, 0, len(positions))
+	left := make([]int, 0, s-len(positions))
+	i := 0
+	for p, x := range ref.blocks[b] {
+		if i < len(positions) && positions[i] == p {
+			block = append(block, x)
+			i++
+		} else {
+			left = append(left, x)
+		}
+	}
+	ref.blocks[b] = left
+	ref.product = ref.multiply(others, len(left), k-1)
+	return block
+}
+
+// first returns the block of pi holding the smallest element left.
+func (ref *refinements) first() int {
+	b := -1
+	for i, block := range ref.blocks {
+		if len(block) > 0 && (b < 0 || block[0] < ref.blocks[b][0]) {
+			b = i
+		}
+	}
+	return b
+}
+
+//  Unrank the refinements of a set partition lexicographicaly.
+/*
+This function takes 3 arguments as parameters :
+- pi : [][]int, a set partition of [|1,n|], blocks in any order.
+- k : int, the number of desired blocks in the result.
+- rank : big.Int, the rank of the desired refinement, 0 <= rank < CountRefinements(pi, k).
+
+Example usage:
+
+	result := intervalunranking.UnrankRefinement([][]int{{1, 2, 4}, {3, 5}}, 3, *big.NewInt(2))
+	fmt.Println(result) // Output: [[1 2 4] [3] [5]]
+*/
+func UnrankRefinement(pi [][]int, k int, rank big.Int) [][]int {
+	ref := newRefinements(pi, k)
+	if k < 1 || rank.Cmp(&ref.product[k]) >= 0 {
+		return nil
+	}
+	r := new(big.Int).Set(&rank)
+	res := make([][]int, 0, k)
+	for j := k; j > 0; j-- {
+		b := ref.first()
+		positions := unrankBlock(ref.column(b, j), len(ref.blocks[b]), r, ref.binomials)
+		res = append(res, ref.remove(b, j, positions))
+	}
+	return res
+}
+
+//  Rank the refinements of a set partition lexicographicaly.
+/*
+This function is the inverse of UnrankRefinement, the result is nil when partition
+is not a refinement of pi into k blocks.
+*/
+func RankRefinement(pi [][]int, k int, partition [][]int) *big.Int {
+	if !between(pi, partition, k, true) {
+		return nil
+	}
+	ref := newRefinements(pi, k)
+	res := big.NewInt(0)
+	j := k
+	for _, block := range sortBlocks(partition) {
+		b := ref.first()
+		positions := make([]int, 0, len(block))
+		i := 0
+		for p, x := range ref.blocks[b] {
+			if i < len(block) && block[i] == x {
+				positions = append(positions, p)
+				i++
+			}
+		}
+		res.Add(res, rankBlock(ref.column(b, j), len(ref.blocks[b]), positions, ref.binomials))
+		ref.remove(b, j, positions)
+		j--
+	}
+	return res
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/AMAURYCU/setpartition_unrank/grayunranking"
 	"github.com/AMAURYCU/setpartition_unrank/parallelunranking"
 	"github.com/AMAURYCU/setpartition_unrank/precalcul"
 	"github.com/AMAURYCU/setpartition_unrank/statistic"
@@ -27,8 +28,8 @@ func listToString(liste []int64) string {
 
 func main() {
 
-	operation := flag.String("operation", "", "Specify operation: A, R or G")
-	mode := flag.String("mode", "", "Specify mode: P or S")
+	operation := flag.String("operation", "", "Specify operation: A, C, R, Q or G")
+	mode := flag.String("mode", "", "Specify mode: P, S or E")
 	flag.Parse()
 
 	if *operation == "" {
@@ -41,11 +42,18 @@ func main() {
 			printUsageAndExit()
 		}
 		handleOperationA(*mode, flag.Args())
+	case "C":
+		handleOperationC(flag.Args())
 	case "R":
 		if *mode == "" {
 			printUsageAndExit()
 		}
 		handleOperationR(*mode, flag.Args())
+	case "Q":
+		if *mode == "" {
+			printUsageAndExit()
+		}
+		handleOperationQ(*mode, flag.Args())
 	case "G":
 		handleOperationG(flag.Args())
 	default:
@@ -64,8 +72,8 @@ func handleOperationA(mode string, args []string) {
 	n, err1 := strconv.Atoi(args[0])
 	k, err2 := strconv.Atoi(args[1])
 
-	if err1 != nil || err2 != nil {
-		fmt.Println("Error: Arguments for Operation A must be numeric.")
+	if err1 != nil || err2 != nil || k < 1 || k > n {
+		fmt.Println("Error: Arguments for Operation A must be numeric with 1 <= n2 <= n1.")
 		printUsageAndExit()
 	}
 
@@ -80,10 +88,24 @@ func handleOperationA(mode string, args []string) {
 
 		c := parallelunranking.Stirling2Columns(n, k).Col1[n]
 		c.Sub(&c, big.NewInt(1))
-		precalcul.StirlingMatrix = statistic.StirlingTriangle(n, k)
+		u := precalcul.NewUnranker(n, k, precalcul.DefaultOptions())
 		for k2 := big.NewInt(0); k2.Cmp(&c) < 1; k2.Add(k2, big.NewInt(1)) {
-			fmt.Println(precalcul.UnrankDichoPre(n, k, *k2, 0), k2)
+			fmt.Println(u.Unrank(k2, 0), k2)
 		}
+	case "E":
+		blocks := make([][]int, k)
+		rank := big.NewInt(0)
+		parallelunranking.Enumerate(n, k, func(rgs []int) bool {
+			for b := range blocks {
+				blocks[b] = blocks[b][:0]
+			}
+			for x, b := range rgs {
+				blocks[b-1] = append(blocks[b-1], x+1)
+			}
+			fmt.Println(blocks, rank)
+			rank.Add(rank, big.NewInt(1))
+			return true
+		})
 	default:
 		fmt.Printf("Error: Invalid mode %s for operation A.\n", mode)
 		printUsageAndExit()
@@ -91,6 +113,31 @@ func handleOperationA(mode string, args []string) {
 
 }
 
+func handleOperationC(args []string) {
+
+	if len(args) != 2 {
+		fmt.Println("Error: Operation C requires exactly 2 arguments.")
+		printUsageAndExit()
+	}
+
+	n, err1 := strconv.Atoi(args[0])
+	k, err2 := strconv.Atoi(args[1])
+
+	if err1 != nil || err2 != nil || k < 1 || k > n {
+		fmt.Println("Error: Arguments for Operation C must be numeric with 1 <= n2 <= n1.")
+		printUsageAndExit()
+	}
+
+	g := grayunranking.NewGray(n, k)
+	rank := big.NewInt(0)
+	g.Enumerate(*rank, func(labels []int, moved int) bool {
+		fmt.Println(labels, rank, moved)
+		rank.Add(rank, big.NewInt(1))
+		return true
+	})
+
+}
+
 func handleOperationR(mode string, args []string) {
 
 	if len(args) != 2 {
@@ -101,8 +148,8 @@ func handleOperationR(mode string, args []string) {
 	n, err1 := strconv.Atoi(args[0])
 	k, err2 := strconv.Atoi(args[1])
 
-	if err1 != nil || err2 != nil {
-		fmt.Println("Error: Arguments for Operation R must be numeric.")
+	if err1 != nil || err2 != nil || k < 1 || k > n {
+		fmt.Println("Error: Arguments for Operation R must be numeric with 1 <= n2 <= n1.")
 		printUsageAndExit()
 	}
 
@@ -115,15 +162,16 @@ func handleOperationR(mode string, args []string) {
 		c := parallelunranking.Stirling2Columns(n, k).Col1[n]
 		c.Sub(&c, big.NewInt(1))
 		r.Rand(rg, &c)
-		fmt.Println(parallelunranking.UnrankDicho(n, k, r, 4))
-		fmt.Println("temps calcul prev col", listToString(parallelunranking.TimePreviousColumn))
+		p, stats := parallelunranking.UnrankStats(n, k, r, 4)
+		fmt.Println(p)
+		fmt.Println("temps calcul prev col", listToString(stats.PreviousColumnTimes))
 		fmt.Println("-----------------------------")
-		fmt.Println("k", statistic.ListToString(parallelunranking.TimePreviousColumnWithK))
+		fmt.Println("k", statistic.ListToString(stats.PreviousColumnK))
 	case "S":
 
-		precalcul.StirlingMatrix = statistic.StirlingTriangle(n, k)
-		r.Rand(rg, precalcul.StirlingMatrix[n][k])
-		fmt.Println(precalcul.UnrankDichoPre(n, k, r, 0), &r)
+		u := precalcul.NewUnranker(n, k, precalcul.DefaultOptions())
+		r.Rand(rg, u.Count())
+		fmt.Println(u.Unrank(&r, 0), &r)
 	default:
 		fmt.Printf("Error: Invalid mode %s for operation R.\n", mode)
 		printUsageAndExit()
@@ -131,6 +179,39 @@ func handleOperationR(mode string, args []string) {
 
 }
 
+func handleOperationQ(mode string, args []string) {
+
+	if len(args) != 3 {
+		fmt.Println("Error: Operation Q requires exactly 3 arguments.")
+		printUsageAndExit()
+	}
+
+	n, err1 := strconv.Atoi(args[0])
+	k, err2 := strconv.Atoi(args[1])
+	q, ok := new(big.Rat).SetString(args[2])
+
+	if err1 != nil || err2 != nil || k < 1 || k > n || !ok || q.Sign() < 0 || q.Cmp(big.NewRat(1, 1)) > 0 {
+		fmt.Println("Error: Arguments for Operation Q must be numeric with 1 <= n2 <= n1, the quantile in [0, 1] (0.25 or 1/4).")
+		printUsageAndExit()
+	}
+
+	r := parallelunranking.QuantileRank(n, k, q)
+	if r == nil {
+		fmt.Println("Error: There is no partition of", n, "elements into", k, "blocks.")
+		os.Exit(1)
+	}
+	switch mode {
+	case "P":
+		fmt.Println(parallelunranking.UnrankDicho(n, k, *r, 4), r)
+	case "S":
+		fmt.Println(precalcul.NewUnranker(n, k, precalcul.DefaultOptions()).Unrank(r, 0), r)
+	default:
+		fmt.Printf("Error: Invalid mode %s for operation Q.\n", mode)
+		printUsageAndExit()
+	}
+
+}
+
 func handleOperationG(args []string) {
 
 	if len(args) != 3 {
@@ -157,14 +238,17 @@ func handleOperationG(args []string) {
 }
 
 func printUsageAndExit() {
-	fmt.Println("Usage: program_name -operation [A/R/G] -mode [P/S] [arguments]")
+	fmt.Println("Usage: program_name -operation [A/C/R/Q/G] -mode [P/S/E] [arguments]")
 	fmt.Println("Operations:")
 	fmt.Println("  A: to generate all partitions of n1 in n2 non-empty disjoints subsets - Requires 2 numeric arguments")
+	fmt.Println("  C: to generate all partitions of n1 in n2 non-empty disjoints subsets in a Gray code order (block labels, rank, moved element) - Requires 2 numeric arguments")
 	fmt.Println("  R: to randomly pickup one partition of n1 in n2 non-empty disjoints subsets - Requires 2 numeric arguments")
+	fmt.Println("  Q: to pickup the partition of n1 in n2 non-empty disjoints subsets at the quantile n3 of the lexicographic order (0.25 or 1/4) - Requires 3 arguments")
 	fmt.Println("  G: to have an overview of the performance of the algorithm partitionning n1 in n2 non-empty disjoints subsets with n3 points - Requires 3 numeric arguments")
 	fmt.Println("Modes:")
 	fmt.Println("  P: parallel")
 	fmt.Println("  S: sequential")
+	fmt.Println("  E: enumeration, operation A only")
 	flag.PrintDefaults()
 	os.Exit(1)
 }
@@ -0,0 +1,308 @@
+// Package multisetunranking provides functions to unrank partitions of a multiset lexicographicaly
+//
+// A multiset such as {1,1,2,3,3,3} is split into k non-empty blocks, each block being
+// itself a multiset. Two partitions that only differ by swapping equal labels are the same
+// partition, so each partition is counted once. Blocks are written as non-decreasing lists and
+// sorted in the lexicographic order, exactly as UnrankDicho does for sets; partitions are then
+// compared block after block. On a set without repetition the order is the one of UnrankDicho.
+package multisetunranking
+
+import (
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// counter memoizes the number of ways to split what remains of the multiset.
+type counter struct {
+	values []int
+	memo   map[string]*big.Int
+}
+
+// newCounter collapses the multiset into its sorted distinct values and their multiplicities.
+func newCounter(multiset []int) (*counter, []int) {
+	sorted := append([]int{}, multiset...)
+	sort.Ints(sorted)
+	values := make([]int, 0)
+	mult := make([]int, 0)
+	for i, v := range sorted {
+		if i == 0 || v != sorted[i-1] {
+			values = append(values, v)
+			mult = append(mult, 0)
+		}
+		mult[len(mult)-1]++
+	}
+	return &counter{values: values, memo: make(map[string]*big.Int)}, mult
+}
+
+func size(rem []int) int {
+	s := 0
+	for _, m := range rem {
+		s += m
+	}
+	return s
+}
+
+// word returns the remaining multiset as a single non-decreasing block of value indices.
+func word(rem []int) []int {
+	res := make([]int, 0)
+	for v, m := range rem {
+		for i := 0; i < m; i++ {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// compareWords compares two blocks in the lexicographic order, a prefix being smaller.
+func compareWords(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
+}
+
+func key(rem []int, j int, bound []int) string {
+	var sb strings.Builder
+	sb.WriteString(strconv.Itoa(j))
+	for _, m := range rem {
+		sb.WriteByte(',')
+		sb.WriteString(strconv.Itoa(m))
+	}
+	sb.WriteByte('|')
+	for _, v := range bound {
+		sb.WriteString(strconv.Itoa(v))
+		sb.WriteByte(',')
+	}
+	return sb.String()
+}
+
+/*
+Visit, in the lexicographic order, every block that can come next:
+the block starts with the smallest remaining value, is not smaller than bound
+and leaves at least rest elements for the following blocks.
+visit receives the block and what remains once it is removed and returns false to stop.
+*/
+func eachBlock(rem []int, bound []int, rest int, visit func(block, left []int) bool) {
+	first := 0
+	for rem[first] == 0 {
+		first++
+	}
+	left := append([]int{}, rem...)
+	left[first]--
+	block := []int{first}
+	total := size(rem)
+
+	var dfs func(check bool) bool
+	dfs = func(check bool) bool {
+		// check: block is a prefix of bound, so it still has to be compared with it
+		candidate := true
+		if check {
+			c := compareWords(block, bound)
+			if c < 0 && block[len(block)-1] != bound[len(block)-1] {
+				return true
+			}
+			candidate = c >= 0
+			check = c < 0
+		}
+		if candidate && total-len(block) >= rest {
+			if !visit(block, left) {
+				return false
+			}
+		}
+		if total-len(block)-1 < rest {
+			return true
+		}
+		for v := block[len(block)-1]; v < len(left); v++ {
+			if left[v] == 0 {
+				continue
+			}
+			left[v]--
+			block = append(block, v)
+			ok := dfs(check)
+			block = block[:len(block)-1]
+			left[v]++
+			if !ok {
+				return false
+			}
+		}
+		return true
+	}
+	dfs(bound != nil)
+}
+
+// count returns the number of ways to split rem into j blocks that are all greater or equal to bound.
+func (c *counter) count(rem []int, j int, bound []int) *big.Int {
+	total := size(rem)
+	if j == 0 {
+		if total == 0 {
+			return big.NewInt(1)
+		}
+		return big.NewInt(0)
+	}
+	if total < j {
+		return big.NewInt(0)
+	}
+	if j == 1 {
+		if compareWords(word(rem), bound) >= 0 {
+			return big.NewInt(1)
+		}
+		return big.NewInt(0)
+	}
+	k := key(rem, j, bound)
+	if res, ok := c.memo[k]; ok {
+		return res
+	}
+	res := big.NewInt(0)
+	eachBlock(rem, bound, j-1, func(block, left []int) bool {
+		res.Add(res, c.count(left, j-1, block))
+		return true
+	})
+	c.memo[k] = res
+	return res
+}
+
+//  Count the partitions of a multiset.
+/*
+Return the number of partitions of multiset into exactly k non-empty blocks,
+partitions that only differ by equal labels being counted once.
+
+Example usage:
+
+	fmt.Println(multisetunranking.Count([]int{1, 1, 2, 3, 3, 3}, 2)) // Output: 11
+*/
+func Count(multiset []int, k int) *big.Int {
+	if k < 1 || k > len(multiset) {
+		return big.NewInt(0)
+	}
+	c, mult := newCounter(multiset)
+	return new(big.Int).Set(c.count(mult, k, nil))
+}
+
+//  Unrank multiset partition lexicographicaly.
+/*
+This function takes 3 arguments as parameters :
+- multiset : []int, the labels to be partitioned, repeated labels allowed, in any order.
+- k : int, the number of desired blocks in the result.
+- rank : big.Int, the rank of the desired partition in the lexicographical order, 0 <= rank < Count(multiset, k).
+
+Every block of the result is sorted and the blocks are sorted lexicographicaly.
+Example usage:
+
+	result := multisetunranking.Unrank([]int{1, 1, 2, 3, 3, 3}, 2, *big.NewInt(3))
+	fmt.Println(result) // Output: [[1 1 2 3] [3 3]]
+*/
+func Unrank(multiset []int, k int, rank big.Int) [][]int {
+	if k < 1 || k > len(multiset) {
+		return nil
+	}
+	c, rem := newCounter(multiset)
+	r := new(big.Int).Set(&rank)
+	res := make([][]int, 0)
+	var bound []int
+	for j := k; j > 1; j-- {
+		var chosen, left []int
+		eachBlock(rem, bound, j-1, func(block, l []int) bool {
+			cnt := c.count(l, j-1, block)
+			if r.Cmp(cnt) < 0 {
+				chosen = append([]int{}, block...)
+				left = append([]int{}, l...)
+				return false
+			}
+			r.Sub(r, cnt)
+			return true
+		})
+		if chosen == nil {
+			return nil
+		}
+		res = append(res, chosen)
+		rem = left
+		bound = chosen
+	}
+	res = append(res, word(rem))
+	for _, block := range res {
+		for i, v := range block {
+			block[i] = c.values[v]
+		}
+	}
+	return res
+}
+
+//  Rank multiset partition lexicographicaly.
+/*
+This function is the inverse of Unrank, the blocks of partition can be given in any order
+and each block in any order. It returns nil when partition is not a partition of multiset
+into k non-empty blocks.
+
+Example usage:
+
+	rank := multisetunranking.Rank([]int{1, 1, 2, 3, 3, 3}, 2, [][]int{{3, 3}, {3, 1, 2, 1}})
+	fmt.Println(rank) // Output: 3
+*/
+func Rank(multiset []int, k int, partition [][]int) *big.Int {
+	if k < 1 || len(partition) != k {
+		return nil
+	}
+	c, rem := newCounter(multiset)
+	index := make(map[int]int)
+	for i, v := range c.values {
+		index[v] = i
+	}
+	// the multiplicities of the labels of partition, which must be those of multiset
+	mult := make([]int, len(rem))
+	blocks := make([][]int, len(partition))
+	for b, block := range partition {
+		if len(block) == 0 {
+			return nil
+		}
+		blocks[b] = make([]int, len(block))
+		for i, v := range block {
+			j, ok := index[v]
+			if !ok {
+				return nil
+			}
+			blocks[b][i] = j
+			mult[j]++
+		}
+		sort.Ints(blocks[b])
+	}
+	for i := range mult {
+		if mult[i] != rem[i] {
+			return nil
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool { return compareWords(blocks[i], blocks[j]) < 0 })
+
+	res := big.NewInt(0)
+	var bound []int
+	for j := k; j > 1; j-- {
+		target := blocks[k-j]
+		var left []int
+		eachBlock(rem, bound, j-1, func(block, l []int) bool {
+			if compareWords(block, target) == 0 {
+				left = append([]int{}, l...)
+				return false
+			}
+			res.Add(res, c.count(l, j-1, block))
+			return true
+		})
+		if left == nil {
+			return nil
+		}
+		rem = left
+		bound = target
+	}
+	return res
+}
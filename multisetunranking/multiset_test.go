@@ -0,0 +1,83 @@
+package multisetunranking
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/AMAURYCU/setpartition_unrank/internal/bruteforce"
+	"github.com/AMAURYCU/setpartition_unrank/parallelunranking"
+)
+
+// bruteForce returns the distinct partitions of multiset into k blocks in the lexicographic order,
+// each one with sorted blocks sorted lexicographicaly, from the set partitions of the positions.
+func bruteForce(multiset []int, k int) [][][]int {
+	seen := make(map[string]bool)
+	res := make([][][]int, 0)
+	parallelunranking.Enumerate(len(multiset), k, func(rgs []int) bool {
+		blocks := make([][]int, k)
+		for x, b := range rgs {
+			blocks[b-1] = append(blocks[b-1], multiset[x])
+		}
+		for _, block := range blocks {
+			sort.Ints(block)
+		}
+		sort.Slice(blocks, func(i, j int) bool { return compareWords(blocks[i], blocks[j]) < 0 })
+		if s := fmt.Sprint(blocks); !seen[s] {
+			seen[s] = true
+			res = append(res, blocks)
+		}
+		return true
+	})
+	sort.Slice(res, func(i, j int) bool {
+		for b := range res[i] {
+			if c := compareWords(res[i][b], res[j][b]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return res
+}
+
+func TestUnrankRank(t *testing.T) {
+	multisets := [][]int{{1, 1, 2, 3, 3, 3}, {2, 2, 2, 2}, {1, 2, 3, 4, 5}, {5, 1, 5, 1, 2, 7, 7}, {4, 4, 1}}
+	for _, multiset := range multisets {
+		for k := 1; k <= len(multiset); k++ {
+			want := bruteForce(multiset, k)
+			bruteforce.Check(t, fmt.Sprint(multiset, k), Count(multiset, k),
+				func(r big.Int) [][]int { return Unrank(multiset, k, r) },
+				func(p [][]int) *big.Int { return Rank(multiset, k, p) }, want)
+		}
+	}
+}
+
+func TestSetOrder(t *testing.T) {
+	// without repeated labels, the order is the one of UnrankDicho
+	multiset := []int{1, 2, 3, 4, 5, 6}
+	for k := 1; k <= len(multiset); k++ {
+		c := Count(multiset, k)
+		for i := int64(0); i < c.Int64(); i++ {
+			got, want := Unrank(multiset, k, *big.NewInt(i)), parallelunranking.UnrankDicho(6, k, *big.NewInt(i), 4)
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Fatalf("Unrank(%v, %d, %d) = %v, UnrankDicho gives %v", multiset, k, i, got, want)
+			}
+		}
+	}
+}
+
+func TestRankInvalid(t *testing.T) {
+	multiset := []int{1, 1, 2, 3, 3, 3}
+	for _, p := range [][][]int{
+		{{1, 1, 2}, {3, 3}},
+		{{1, 1, 2, 3}, {3, 3, 3}},
+		{{1, 1, 9}, {3, 3, 3}},
+		{{1, 1, 2, 3, 3, 3}, {}},
+		{{1, 1, 2, 3, 3, 3}},
+	} {
+		if r := Rank(multiset, 2, p); r != nil {
+			t.Errorf("Rank(%v, 2, %v) = %v, want nil", multiset, p, r)
+		}
+	}
+}
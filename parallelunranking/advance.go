@@ -0,0 +1,110 @@
+package parallelunranking
+
+import (
+	"math/big"
+
+	"github.com/AMAURYCU/setpartition_unrank/types"
+)
+
+//  Skip ahead in the lexicographic order.
+/*
+Return the set partition delta ranks after p in the order of UnrankDicho, delta may be negative.
+p is a set partition of [|1,n|] into k blocks, the result being nil when it is not one or when the
+rank falls out of [0, S(n,k)). The blocks shared by p and the result are kept and the search of
+UnrankDicho, with the formula whichS3, only runs on the others.
+
+Example usage:
+
+	result := parallelunranking.Advance([][]int{{1, 2, 3}, {4}, {5}}, big.NewInt(2), 4)
+	fmt.Println(result) // Output: [[1 2 5] [3] [4]]
+*/
+func Advance(p [][]int, delta *big.Int, whichS3 int) [][]int {
+	return newUnrankState(whichS3).advance(p, delta)
+}
+
+// Advance is the function Advance with the options of the Unranker.
+func (u *Unranker) Advance(p [][]int, delta *big.Int) [][]int {
+	return u.state().advance(p, delta)
+}
+
+func (s *unrankState) advance(p [][]int, delta *big.Int) [][]int {
+	partition := types.NewPartition(p)
+	n, k := partition.N(), partition.K()
+	if k < 1 || partition.Validate(n, k) != nil {
+		return nil
+	}
+	rgs := partition.RGS()
+	lo, count, _ := prefixRanks(n, k, rgs, k-1)
+	target := new(big.Int).Add(&lo[k-1], delta)
+	// i is the number of blocks of p kept
+	i := k - 1
+	for ; i >= 0; i-- {
+		hi := new(big.Int).Add(&lo[i], &count[i])
+		if target.Cmp(&lo[i]) >= 0 && target.Cmp(hi) < 0 {
+			break
+		}
+	}
+	if i < 0 {
+		return nil
+	}
+	blocks := types.RGSToBlocks(rgs)[:i]
+	remaining := make([]int, 0, n)
+	for x, b := range rgs {
+		if b > i {
+			remaining = append(remaining, x+1)
+		}
+	}
+	target.Sub(target, &lo[i])
+	for _, block := range types.LexicographicPermutationUnrank(len(remaining), s.unrankPositions(len(remaining), k-i, target, nil)) {
+		for y, x := range block {
+			block[y] = remaining[x-1]
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+//  Rank interval of the set partitions starting with given blocks.
+/*
+Return lo and hi such that the set partitions of [|1,n|] into k blocks whose first blocks are the
+blocks of prefix, in this order, are the ones of rank lo <= r < hi in the order of UnrankDicho.
+The interval is empty (lo = hi = 0) when no partition starts with prefix.
+
+Example usage:
+
+	lo, hi := parallelunranking.PrefixInterval(5, 3, [][]int{{1, 2}})
+	fmt.Println(lo, hi) // Output: 7 10
+*/
+func PrefixInterval(n, k int, prefix [][]int) (*big.Int, *big.Int) {
+	empty := func() (*big.Int, *big.Int) { return big.NewInt(0), big.NewInt(0) }
+	if len(prefix) > k || k < 1 || k > n {
+		return empty()
+	}
+	rgs := make([]int, n)
+	size := 0
+	for j, block := range prefix {
+		for _, x := range block {
+			if x < 1 || x > n || rgs[x-1] != 0 {
+				return empty()
+			}
+			rgs[x-1] = j + 1
+			size++
+		}
+	}
+	blocks := len(prefix)
+	if blocks == k {
+		// a whole partition, its last block must hold every element left
+		if size != n {
+			return empty()
+		}
+		blocks = k - 1
+	}
+	lo, count, ok := prefixRanks(n, k, rgs, blocks)
+	if !ok || count[blocks].Sign() == 0 {
+		return empty()
+	}
+	if len(prefix) == k {
+		count[blocks].SetInt64(1)
+	}
+	return &lo[blocks], new(big.Int).Add(&lo[blocks], &count[blocks])
+}
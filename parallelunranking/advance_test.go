@@ -0,0 +1,92 @@
+package parallelunranking
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/AMAURYCU/setpartition_unrank/types"
+)
+
+func TestAdvance(t *testing.T) {
+	for n := 1; n <= 6; n++ {
+		for k := 1; k <= n; k++ {
+			count := stirling2(n, k)
+			for i := int64(0); i < count; i++ {
+				p := UnrankDicho(n, k, *big.NewInt(i), 3)
+				for j := int64(-1); j <= count; j++ {
+					got := Advance(p, big.NewInt(j-i), int(i%5))
+					if u := NewUnranker(DefaultOptions()).Advance(p, big.NewInt(j-i)); fmt.Sprint(u) != fmt.Sprint(got) {
+						t.Fatalf("Unranker.Advance(%v, %d) = %v, Advance gives %v", p, j-i, u, got)
+					}
+					if j < 0 || j >= count {
+						if got != nil {
+							t.Fatalf("Advance(%v, %d) = %v, want nil", p, j-i, got)
+						}
+						continue
+					}
+					if want := UnrankDicho(n, k, *big.NewInt(j), 3); fmt.Sprint(got) != fmt.Sprint(want) {
+						t.Fatalf("Advance(%v, %d) = %v, want %v", p, j-i, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestAdvanceInvalid(t *testing.T) {
+	for _, p := range [][][]int{{{1, 2}, {2, 3}}, {{1}, {3}}, {{1, 2}, {}, {3}}, {{0, 1}, {2}}, {}} {
+		for _, delta := range []int64{0, 1} {
+			if got := Advance(p, big.NewInt(delta), 4); got != nil {
+				t.Errorf("Advance(%v, %d) = %v, want nil", p, delta, got)
+			}
+		}
+	}
+}
+
+func TestPrefixInterval(t *testing.T) {
+	for n := 1; n <= 6; n++ {
+		for k := 1; k <= n; k++ {
+			all := make([][][]int, 0)
+			Enumerate(n, k, func(rgs []int) bool {
+				all = append(all, types.RGSToBlocks(rgs))
+				return true
+			})
+			for _, p := range all {
+				for j := 0; j <= k; j++ {
+					prefix := p[:j]
+					// the ranks of the partitions starting with prefix, found one by one
+					lo, hi := -1, -1
+					for r, q := range all {
+						if fmt.Sprint(q[:j]) == fmt.Sprint(prefix) {
+							if lo < 0 {
+								lo = r
+							}
+							hi = r + 1
+						}
+					}
+					gotLo, gotHi := PrefixInterval(n, k, prefix)
+					if gotLo.Int64() != int64(lo) || gotHi.Int64() != int64(hi) {
+						t.Fatalf("PrefixInterval(%d, %d, %v) = %v, %v, want %d, %d", n, k, prefix, gotLo, gotHi, lo, hi)
+					}
+				}
+			}
+		}
+	}
+	for _, prefix := range [][][]int{{{2}}, {{1, 1}}, {{1}, {1, 2}}, {{0, 1}}, {{1, 6}}, {{1}, {2}, {3}, {4}}, {{1}, {2}, {3, 4}}} {
+		if lo, hi := PrefixInterval(5, 3, prefix); lo.Sign() != 0 || hi.Sign() != 0 {
+			t.Fatalf("PrefixInterval(5, 3, %v) = %v, %v, want an empty interval", prefix, lo, hi)
+		}
+	}
+}
+
+func TestPrefixIntervalEmpty(t *testing.T) {
+	for _, c := range []struct {
+		n, k   int
+		prefix [][]int
+	}{{3, 4, [][]int{{1}}}, {3, 4, nil}, {3, 0, nil}, {0, 1, nil}, {3, 2, [][]int{{2}}}, {3, 2, [][]int{{1, 4}}}} {
+		if lo, hi := PrefixInterval(c.n, c.k, c.prefix); lo.Sign() != 0 || hi.Sign() != 0 {
+			t.Errorf("PrefixInterval(%d, %d, %v) = %v, %v, want 0, 0", c.n, c.k, c.prefix, lo, hi)
+		}
+	}
+}
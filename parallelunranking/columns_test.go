@@ -0,0 +1,33 @@
+package parallelunranking
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/AMAURYCU/setpartition_unrank/types"
+)
+
+func TestStirling2Columns(t *testing.T) {
+	for n := 1; n <= 70; n += 3 {
+		triangle := types.StirlingColumns(n, n)
+		for k := 1; k <= n; k++ {
+			methods := map[string]*types.CoupleColumns{"recurrence": Stirling2ColumnsRecurrence(n, k)}
+			if k >= 2 {
+				for _, workers := range []int{1, 3, 8} {
+					methods[fmt.Sprint("explicit on ", workers, " workers")] = Stirling2ColumnsExplicit(n, k, workers)
+				}
+			}
+			for name, couple := range methods {
+				if len(couple.Col0) != n+1 || len(couple.Col1) != n+1 {
+					t.Fatalf("%s(%d, %d) has %d and %d lines, want %d", name, n, k, len(couple.Col0), len(couple.Col1), n+1)
+				}
+				for i := 0; i <= n; i++ {
+					if couple.Col0[i].Cmp(&triangle[k-1][i]) != 0 || couple.Col1[i].Cmp(&triangle[k][i]) != 0 {
+						t.Fatalf("%s(%d, %d) gives S(%d, %d) = %v and S(%d, %d) = %v, want %v and %v", name, n, k,
+							i, k-1, &couple.Col0[i], i, k, &couple.Col1[i], &triangle[k-1][i], &triangle[k][i])
+					}
+				}
+			}
+		}
+	}
+}
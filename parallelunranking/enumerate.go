@@ -0,0 +1,124 @@
+package parallelunranking
+
+/*
+enumerator holds the state of Enumerate. rgs[x-1] is the block of x, the elements that are not yet
+in one of the k-1 first blocks being left in the block k. The elements out of the blocks form a
+doubly linked list, 0 and n+1 being its ends.
+*/
+type enumerator struct {
+	n, k        int
+	rgs         []int
+	left, right []int
+	visit       func(rgs []int) bool
+}
+
+func (e *enumerator) take(x, j int) {
+	e.rgs[x-1] = j
+	e.right[e.left[x]] = e.right[x]
+	e.left[e.right[x]] = e.left[x]
+}
+
+func (e *enumerator) release(x int) {
+	e.rgs[x-1] = e.k
+	e.right[e.left[x]] = x
+	e.left[e.right[x]] = x
+}
+
+// start opens the block j with the smallest free element, free elements being out of the blocks.
+func (e *enumerator) start(j, free int) bool {
+	if j == e.k {
+		return e.visit(e.rgs)
+	}
+	x := e.right[0]
+	e.take(x, j)
+	ok := e.extend(j, x, free-1)
+	e.release(x)
+	return ok
+}
+
+// extend visits the partitions whose block j starts with the current elements, last being its largest one.
+func (e *enumerator) extend(j, last, free int) bool {
+	// closing the block comes first since a prefix is smaller, the k-j blocks left need free >= k-j
+	if free >= e.k-j && !e.start(j+1, free) {
+		return false
+	}
+	if free-1 < e.k-j {
+		return true
+	}
+	if free-1 == e.k-j {
+		return e.singletons(j, last)
+	}
+	for x := e.right[last]; x <= e.n; x = e.right[x] {
+		e.take(x, j)
+		ok := e.extend(j, x, free-1)
+		e.release(x)
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+singletons visits the partitions where the block j ends with an element x after last and the k-j free
+elements left are the singletons j+1, ..., k, x running over the free elements. Only x and its successor
+change block from one partition to the next, so the chain of singletons is not rebuilt for each one.
+*/
+func (e *enumerator) singletons(j, last int) bool {
+	x := e.right[last]
+	if x > e.n {
+		return true
+	}
+	// the free elements before x are the first singletons
+	label := j
+	for y := e.right[0]; y <= e.n; y = e.right[y] {
+		if y == x {
+			e.rgs[y-1] = j
+			continue
+		}
+		label++
+		e.rgs[y-1] = label
+	}
+	ok := e.visit(e.rgs)
+	for ; ok && e.right[x] <= e.n; x = e.right[x] {
+		e.rgs[x-1], e.rgs[e.right[x]-1] = e.rgs[e.right[x]-1], j
+		ok = e.visit(e.rgs)
+	}
+	for y := e.right[0]; y <= e.n; y = e.right[y] {
+		e.rgs[y-1] = e.k
+	}
+	return ok
+}
+
+//  Enumerate set partitions lexicographicaly.
+/*
+Call visit on the restricted growth strings of the set partitions of [|1,n|] into k blocks, in the
+order of UnrankDicho (rank 0, 1, ...), until visit returns false. The same buffer is used for every
+partition, so rgs must not be modified nor kept. The time spent between two calls to visit is constant
+on average.
+
+Example usage:
+
+	parallelunranking.Enumerate(4, 2, func(rgs []int) bool {
+		fmt.Println(rgs) // Output: [1 2 2 2], [1 1 2 2], [1 1 1 2], ...
+		return true
+	})
+*/
+func Enumerate(n, k int, visit func(rgs []int) bool) {
+	if k < 1 || k > n {
+		return
+	}
+	e := &enumerator{n: n, k: k, rgs: make([]int, n), left: make([]int, n+2), right: make([]int, n+2), visit: visit}
+	for x := 0; x <= n+1; x++ {
+		if x <= n {
+			e.right[x] = x + 1
+		}
+		if x > 0 {
+			e.left[x] = x - 1
+		}
+		if x >= 1 && x <= n {
+			e.rgs[x-1] = k
+		}
+	}
+	e.start(1, n)
+}
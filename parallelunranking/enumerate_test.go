@@ -0,0 +1,75 @@
+package parallelunranking
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/AMAURYCU/setpartition_unrank/types"
+)
+
+func TestEnumerate(t *testing.T) {
+	for n := 0; n <= 8; n++ {
+		for k := -1; k <= n+1; k++ {
+			i := int64(0)
+			Enumerate(n, k, func(rgs []int) bool {
+				want := UnrankRGS(n, k, *big.NewInt(i), 3)
+				if fmt.Sprint(rgs) != fmt.Sprint(want) {
+					t.Fatalf("Enumerate(%d, %d) gives %v at %d, want %v", n, k, rgs, i, want)
+				}
+				i++
+				return true
+			})
+			want := int64(0)
+			if k >= 1 {
+				want = stirling2(n, k)
+			}
+			if i != want {
+				t.Fatalf("Enumerate(%d, %d) visits %d partitions, want %d", n, k, i, want)
+			}
+		}
+	}
+}
+
+func TestEnumerateStop(t *testing.T) {
+	seen := 0
+	Enumerate(6, 3, func(rgs []int) bool {
+		seen++
+		if blocks := types.RGSToBlocks(rgs); len(blocks) != 3 {
+			t.Fatalf("Enumerate(6, 3) gives %v", blocks)
+		}
+		return seen < 10
+	})
+	if seen != 10 {
+		t.Fatalf("Enumerate(6, 3) visits %d partitions after stopping at 10", seen)
+	}
+}
+
+// perPartition returns the smallest time per partition of Enumerate(n, k) over a few runs.
+func perPartition(n, k int) time.Duration {
+	best := time.Duration(0)
+	for run := 0; run < 5; run++ {
+		count := 0
+		start := time.Now()
+		Enumerate(n, k, func(rgs []int) bool {
+			count++
+			return true
+		})
+		if d := time.Since(start) / time.Duration(count); run == 0 || d < best {
+			best = d
+		}
+	}
+	return best
+}
+
+func TestEnumerateConstantTime(t *testing.T) {
+	if testing.Short() {
+		t.Skip("timing test")
+	}
+	// the last blocks are mostly singletons when k is close to n
+	small, large := perPartition(100, 99), perPartition(1600, 1599)
+	if large > 4*small+50*time.Nanosecond {
+		t.Errorf("Enumerate takes %v per partition for n = 1600, %v for n = 100", large, small)
+	}
+}
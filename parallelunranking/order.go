@@ -0,0 +1,221 @@
+package parallelunranking
+
+import (
+	"math/big"
+
+	"github.com/AMAURYCU/setpartition_unrank/types"
+)
+
+// Order is a total order on the set partitions of [|1,n|] into k blocks.
+type Order int
+
+const (
+	// BlockLex is the order of UnrankDicho, the blocks sorted by smallest element are compared lexicographicaly.
+	BlockLex Order = iota
+	// RGSLex compares the restricted growth strings lexicographicaly, as Knuth's Algorithm H and SageMath do.
+	RGSLex
+	// RGSColex compares the restricted growth strings from their last letter.
+	RGSColex
+)
+
+// completions returns D[r][m], the number of ways to write r more letters of a restricted growth string of maximum m ending with maximum k.
+func completions(n, k int) [][]big.Int {
+	res := make([][]big.Int, n+1)
+	for r := range res {
+		res[r] = make([]big.Int, k+2)
+	}
+	res[0][k].SetInt64(1)
+	for r := 1; r <= n; r++ {
+		for m := 0; m <= k; m++ {
+			res[r][m].Mul(big.NewInt(int64(m)), &res[r-1][m])
+			res[r][m].Add(&res[r][m], &res[r-1][m+1])
+		}
+	}
+	return res
+}
+
+func unrankRGSLex(n, k int, rank big.Int) []int {
+	D := completions(n, k)
+	r := new(big.Int).Set(&rank)
+	res := make([]int, n)
+	m := 0
+	for i := range res {
+		d := &D[n-i-1][m]
+		all := new(big.Int).Mul(big.NewInt(int64(m)), d)
+		if d.Sign() > 0 && r.Cmp(all) < 0 {
+			q, rem := new(big.Int).QuoRem(r, d, new(big.Int))
+			res[i] = int(q.Int64()) + 1
+			r = rem
+		} else {
+			r.Sub(r, all)
+			m++
+			res[i] = m
+		}
+	}
+	return res
+}
+
+func rankRGSLex(n, k int, rgs []int) *big.Int {
+	D := completions(n, k)
+	res := big.NewInt(0)
+	m := 0
+	for i, a := range rgs {
+		res.Add(res, new(big.Int).Mul(big.NewInt(int64(a-1)), &D[n-i-1][m]))
+		if a > m {
+			m = a
+		}
+	}
+	return res
+}
+
+/*
+In the colexicographic order the letters are chosen from the last one. ok[m] tells whether the
+letters chosen so far can follow a prefix of maximum m, and a prefix of length i of maximum m
+can be written in S(i, m) = S[m][i] ways.
+*/
+type colex struct {
+	n, k int
+	S    [][]big.Int
+	ok   []bool
+}
+
+func newColex(n, k int) *colex {
+	c := &colex{n: n, k: k, S: types.StirlingColumns(n, k), ok: make([]bool, k+1)}
+	c.ok[k] = true
+	return c
+}
+
+// next returns the validity of the suffix preceded by the letter v.
+func (c *colex) next(v int) []bool {
+	res := make([]bool, c.k+1)
+	for m := 0; m <= c.k; m++ {
+		if v <= m+1 {
+			M := m
+			if v > M {
+				M = v
+			}
+			res[m] = M <= c.k && c.ok[M]
+		}
+	}
+	return res
+}
+
+// count returns the number of words ending with the suffix valid for ok, the prefix having length i.
+func (c *colex) count(i int, ok []bool) *big.Int {
+	res := big.NewInt(0)
+	for m, b := range ok {
+		if b {
+			res.Add(res, &c.S[m][i])
+		}
+	}
+	return res
+}
+
+func unrankRGSColex(n, k int, rank big.Int) []int {
+	c := newColex(n, k)
+	r := new(big.Int).Set(&rank)
+	res := make([]int, n)
+	for i := n - 1; i >= 0; i-- {
+		for v := 1; v <= k; v++ {
+			ok := c.next(v)
+			w := c.count(i, ok)
+			if r.Cmp(w) < 0 || v == k {
+				res[i] = v
+				c.ok = ok
+				break
+			}
+			r.Sub(r, w)
+		}
+	}
+	return res
+}
+
+func rankRGSColex(n, k int, rgs []int) *big.Int {
+	c := newColex(n, k)
+	res := big.NewInt(0)
+	for i := n - 1; i >= 0; i-- {
+		for v := 1; v < rgs[i]; v++ {
+			res.Add(res, c.count(i, c.next(v)))
+		}
+		c.ok = c.next(rgs[i])
+	}
+	return res
+}
+
+//  Unrank set partition in the given order.
+/*
+Return the set partition of [|1,n|] into k blocks of rank rank in the given order, sorted like UnrankDicho does,
+or nil when k is not in [|1,n|] or rank is not in [|0,S(n,k)-1|].
+
+Example usage:
+
+	result := parallelunranking.UnrankInOrder(4, 2, *big.NewInt(0), parallelunranking.RGSLex)
+	fmt.Println(result) // Output: [[1 2 3] [4]]
+*/
+func UnrankInOrder(n, k int, rank big.Int, order Order) [][]int {
+	if rank.Sign() < 0 || rank.Cmp(stirling(n, k)) >= 0 {
+		return nil
+	}
+	switch order {
+	case RGSLex:
+		return types.RGSToBlocks(unrankRGSLex(n, k, rank))
+	case RGSColex:
+		return types.RGSToBlocks(unrankRGSColex(n, k, rank))
+	default:
+		return UnrankDicho(n, k, rank, 4)
+	}
+}
+
+//  Rank set partition in the given order.
+/*
+This function is the inverse of UnrankInOrder, the blocks can be given in any order.
+The result is nil when partition is not a set partition of [|1,n|] into k blocks.
+*/
+func RankInOrder(n, k int, partition [][]int, order Order) *big.Int {
+	p := types.NewPartition(partition)
+	if p.Validate(n, k) != nil {
+		return nil
+	}
+	rgs := p.RGS()
+	switch order {
+	case RGSLex:
+		return rankRGSLex(n, k, rgs)
+	case RGSColex:
+		return rankRGSColex(n, k, rgs)
+	default:
+		return RankRGS(n, k, rgs)
+	}
+}
+
+//  Translate a rank from one order to another.
+/*
+Return the rank in the order to of the set partition of [|1,n|] into k blocks of rank rank in the order from,
+or nil when k is not in [|1,n|] or rank is not in [|0,S(n,k)-1|].
+
+Example usage:
+
+	rank := parallelunranking.TranslateRank(5, 3, *big.NewInt(0), parallelunranking.BlockLex, parallelunranking.RGSLex)
+	fmt.Println(rank) // Output: 24
+*/
+func TranslateRank(n, k int, rank big.Int, from, to Order) *big.Int {
+	if rank.Sign() < 0 || rank.Cmp(stirling(n, k)) >= 0 {
+		return nil
+	}
+	var rgs []int
+	switch from {
+	case RGSLex:
+		rgs = unrankRGSLex(n, k, rank)
+	case RGSColex:
+		rgs = unrankRGSColex(n, k, rank)
+	default:
+		rgs = UnrankRGS(n, k, rank, 4)
+	}
+	switch to {
+	case RGSLex:
+		return rankRGSLex(n, k, rgs)
+	case RGSColex:
+		return rankRGSColex(n, k, rgs)
+	default:
+		return RankRGS(n, k, rgs)
+	}
+}
@@ -0,0 +1,120 @@
+package parallelunranking
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/AMAURYCU/setpartition_unrank/types"
+)
+
+// allRGS returns the restricted growth strings of the set partitions of [|1,n|] into k blocks.
+func allRGS(n, k int) [][]int {
+	res := make([][]int, 0)
+	rgs := make([]int, n)
+	var grow func(x, m int)
+	grow = func(x, m int) {
+		if x == n {
+			if m == k {
+				res = append(res, append([]int{}, rgs...))
+			}
+			return
+		}
+		for a := 1; a <= m+1 && a <= k; a++ {
+			rgs[x] = a
+			grow(x+1, max(m, a))
+		}
+	}
+	grow(0, 0)
+	return res
+}
+
+// lexLess compares a and b lexicographicaly, from the letter first, first+step...
+func lexLess(a, b []int, first, step int) bool {
+	for i := first; i >= 0 && i < len(a); i += step {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+// sorted returns the restricted growth strings of the set partitions of [|1,n|] into k blocks sorted in the order.
+func sorted(n, k int, order Order) [][]int {
+	res := allRGS(n, k)
+	sort.Slice(res, func(i, j int) bool {
+		switch order {
+		case RGSLex:
+			return lexLess(res[i], res[j], 0, 1)
+		case RGSColex:
+			return lexLess(res[i], res[j], n-1, -1)
+		default:
+			return less(types.RGSToBlocks(res[i]), types.RGSToBlocks(res[j]))
+		}
+	})
+	return res
+}
+
+func TestOrders(t *testing.T) {
+	orders := []Order{BlockLex, RGSLex, RGSColex}
+	for n := 1; n <= 7; n++ {
+		for k := 1; k <= n; k++ {
+			lists := make([][][]int, len(orders))
+			for o, order := range orders {
+				lists[o] = sorted(n, k, order)
+				for i, rgs := range lists[o] {
+					rank := *big.NewInt(int64(i))
+					want := types.RGSToBlocks(rgs)
+					if got := UnrankInOrder(n, k, rank, order); fmt.Sprint(got) != fmt.Sprint(want) {
+						t.Fatalf("UnrankInOrder(%d, %d, %d, %d) = %v, want %v", n, k, i, order, got, want)
+					}
+					if r := RankInOrder(n, k, want, order); r.Cmp(&rank) != 0 {
+						t.Fatalf("RankInOrder(%d, %d, %v, %d) = %v, want %d", n, k, want, order, r, i)
+					}
+				}
+			}
+			// the rank of each partition in each order, by restricted growth string
+			ranks := make(map[string][]int64)
+			for o := range orders {
+				for i, rgs := range lists[o] {
+					key := fmt.Sprint(rgs)
+					if ranks[key] == nil {
+						ranks[key] = make([]int64, len(orders))
+					}
+					ranks[key][o] = int64(i)
+				}
+			}
+			for _, r := range ranks {
+				for from := range orders {
+					for to := range orders {
+						if got := TranslateRank(n, k, *big.NewInt(r[from]), orders[from], orders[to]); got.Cmp(big.NewInt(r[to])) != 0 {
+							t.Fatalf("TranslateRank(%d, %d, %d, %d, %d) = %v, want %d", n, k, r[from], orders[from], orders[to], got, r[to])
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestOrdersInvalid(t *testing.T) {
+	for _, o := range []Order{BlockLex, RGSLex, RGSColex} {
+		for _, c := range []struct {
+			k    int
+			rank *big.Int
+		}{{4, big.NewInt(0)}, {0, big.NewInt(0)}, {2, big.NewInt(3)}, {2, big.NewInt(-1)}} {
+			if p := UnrankInOrder(3, c.k, *c.rank, o); p != nil {
+				t.Errorf("UnrankInOrder(3, %d, %v, %d) = %v, want nil", c.k, c.rank, o, p)
+			}
+			if r := TranslateRank(3, c.k, *c.rank, o, BlockLex); r != nil {
+				t.Errorf("TranslateRank(3, %d, %v, %d, %d) = %v, want nil", c.k, c.rank, o, BlockLex, r)
+			}
+		}
+		for _, p := range [][][]int{{{1, 2}, {2, 3}}, {{1}, {3}}, {{1, 2, 3}}} {
+			if r := RankInOrder(3, 2, p, o); r != nil {
+				t.Errorf("RankInOrder(3, 2, %v, %d) = %v, want nil", p, o, r)
+			}
+		}
+	}
+}
@@ -7,21 +7,63 @@ package parallelunranking
 
 import (
 	"math/big"
+	"sync"
 	"time"
 
 	"github.com/AMAURYCU/setpartition_unrank/types"
 )
 
-var WaitingTime int64
-
+// StirlingColumn0 and StirlingColumn1 are the columns S(., k-1) and S(., k) read by S3v1, ..., S3v5, each unrank having its own.
 var StirlingColumn0 []big.Int
 var StirlingColumn1 []big.Int
 
-var TimePreviousColumn []int64
-var TimePreviousColumnWithK []int64
+// BinomialCacheWords bounds the size in machine words of the binomial coefficients kept by each unrank and each Unranker, 0 disables the cache.
+var BinomialCacheWords = types.DefaultBinomialCacheWords
+
+var vs3 = [5](func(s *unrankState, res *big.Int, n, k int, swap bool, d int)){(*unrankState).s3v1, (*unrankState).s3v2, (*unrankState).s3v3, (*unrankState).s3v4, (*unrankState).s3v5}
+
+// FixedWidth unranks on 64 or 128 bits machine words instead of math/big when S(n,k) < 2^128, with the same results.
+var FixedWidth = true
+
+// PipelineDepth is the number of previous Stirling columns computed ahead of the block being unranked.
+var PipelineDepth = DefaultPipelineDepth
 
-var vs3 = [5](func(n, k int, swap bool, d int) big.Int){S3v1, S3v2, S3v3, S3v4, S3v5}
-var TimeTotal int64
+// PipelineWords bounds the machine words of the columns computed ahead, one column being always computed ahead.
+var PipelineWords = DefaultPipelineWords
+
+// DefaultPipelineDepth and DefaultPipelineWords are the default values of PipelineDepth and PipelineWords.
+const (
+	DefaultPipelineDepth = 2
+	DefaultPipelineWords = 1 << 24
+)
+
+/*
+unrankState holds what belongs to one unrank : its options, the Stirling columns S(., k-1) and S(., k)
+of the block being unranked, swapped after each block, and the binomial coefficients, big.Int buffers
+and unrankers on machine words it uses, those of its Unranker if any.
+*/
+type unrankState struct {
+	opts       Options
+	col0, col1 []big.Int
+	binomials  *types.BinomialCache
+	scratch    *bigPool
+	fixed      *fixedWidthCache
+}
+
+// packageOptions returns the options given by the package variables, with the S3 formula whichS3.
+func packageOptions(whichS3 int) Options {
+	return Options{WhichS3: whichS3, S3Workers: S3Workers, S3ParallelThreshold: S3ParallelThreshold, SearchFanOut: SearchFanOut, PipelineDepth: PipelineDepth, PipelineWords: PipelineWords, FixedWidth: FixedWidth, Columns: Columns, ColumnsWorkers: ColumnsWorkers}
+}
+
+// newUnrankState returns the state of an unrank done outside of an Unranker, with the options of the package variables.
+func newUnrankState(whichS3 int) *unrankState {
+	return &unrankState{opts: packageOptions(whichS3), binomials: types.NewBinomialCache(BinomialCacheWords), scratch: new(bigPool)}
+}
+
+// columnsState returns the state of S3v1, ..., S3v5, reading StirlingColumn0 and StirlingColumn1.
+func columnsState() *unrankState {
+	return &unrankState{opts: packageOptions(0), col0: StirlingColumn0, col1: StirlingColumn1}
+}
 
 func min(a, b int) int {
 	if a < b {
@@ -39,32 +81,38 @@ d - last element of the unranked prefix
 u - length of the prefix
 */
 func S3v1(n, k int, swap bool, d int) big.Int {
+	var res big.Int
+	columnsState().s3v1(&res, n, k, swap, d)
+	return res
+}
+
+// s3v1 is S3v1 writing its result in res.
+func (s *unrankState) s3v1(res *big.Int, n, k int, swap bool, d int) {
 	if d < 0 {
-		return *big.NewInt(0)
+		res.SetInt64(0)
+		return
 	}
 	if d == 0 && !(k-1 <= n && k-1 >= 0) {
-		return *big.NewInt(0)
+		res.SetInt64(0)
+		return
 	}
-	var res *big.Int
 	if !swap {
-		res = new(big.Int).Set(&StirlingColumn0[n])
+		res.Set(&s.col0[n])
 	} else {
-		res = new(big.Int).Set(&StirlingColumn1[n])
+		res.Set(&s.col1[n])
 	}
-	u := 0
-	b := big.NewInt(1)
-	for u < min(n-d, n-k+1) {
-		b.Mul(b, big.NewInt(int64(n-d-u)))
-		u++
-		b.Div(b, big.NewInt(int64(u)))
+	row := s.binomials.Row(n-d, min(n-d, n-k+1))
+	tmp := s.scratch.get()
+	defer s.scratch.put(tmp)
+	for u := 1; u <= min(n-d, n-k+1); u++ {
+		b := &row[u]
 
 		if !swap {
-			res.Add(res, new(big.Int).Mul(&StirlingColumn0[n-u], b))
+			res.Add(res, tmp.Mul(&s.col0[n-u], b))
 		} else {
-			res.Add(res, new(big.Int).Mul(&StirlingColumn1[n-u], b))
+			res.Add(res, tmp.Mul(&s.col1[n-u], b))
 		}
 	}
-	return *res
 }
 
 /*
@@ -78,51 +126,53 @@ func S3v1(n, k int, swap bool, d int) big.Int {
 		u - length of the prefix
 */
 func S3v2(n, k int, swap bool, d int) big.Int {
+	var res big.Int
+	columnsState().s3v2(&res, n, k, swap, d)
+	return res
+}
+
+// s3v2 is S3v2 writing its result in res.
+func (s *unrankState) s3v2(res *big.Int, n, k int, swap bool, d int) {
 	if d < 0 {
-		return *big.NewInt(0)
+		res.SetInt64(0)
+		return
 	}
 	if d == 0 && !(k-1 <= n && k-1 >= 0) {
-		return *big.NewInt(0)
+		res.SetInt64(0)
+		return
 	}
-	var res *big.Int
 	if !swap {
-		res = new(big.Int).Set(&StirlingColumn0[n])
+		res.Set(&s.col0[n])
 	} else {
-		res = new(big.Int).Set(&StirlingColumn1[n])
+		res.Set(&s.col1[n])
 	}
 
 	if d >= k-1 {
 		if !swap {
-			res.Add(res, &StirlingColumn0[d])
+			res.Add(res, &s.col0[d])
 		} else {
-			res.Add(res, &StirlingColumn1[d])
+			res.Add(res, &s.col1[d])
 		}
 	}
-	u := 0
-	b := big.NewInt(1)
-	for u < min((n-d)/2, n-k+1) {
-		b.Mul(b, big.NewInt(int64(n-d-u)))
-		u++
-		b.Div(b, big.NewInt(int64(u)))
+	row := s.binomials.Row(n-d, min((n-d)/2, n-k+1))
+	s.sumS3(res, min((n-d)/2, n-k+1), func(u int, tmp, aux *big.Int) {
+		b := &row[u]
 
 		if (d+u >= k-1) && (u < (n-d)/2 || (u == (n-d)/2 && (n-d)%2 == 1)) {
-			var tmp *big.Int
 			if !swap {
-				tmp = new(big.Int).Add(&StirlingColumn0[n-u], &StirlingColumn0[d+u])
+				aux.Add(&s.col0[n-u], &s.col0[d+u])
 			} else {
-				tmp = new(big.Int).Add(&StirlingColumn1[n-u], &StirlingColumn1[d+u])
+				aux.Add(&s.col1[n-u], &s.col1[d+u])
 			}
-			tmp.Mul(tmp, b)
-			res.Add(res, tmp)
+			tmp.Mul(aux, b)
 		} else {
 			if !swap {
-				res.Add(res, new(big.Int).Mul(&StirlingColumn0[n-u], b))
+				tmp.Mul(&s.col0[n-u], b)
 			} else {
-				res.Add(res, new(big.Int).Mul(&StirlingColumn1[n-u], b))
+				tmp.Mul(&s.col1[n-u], b)
 			}
 		}
-	}
-	return *res
+	})
 }
 
 /*
@@ -134,44 +184,52 @@ func S3v2(n, k int, swap bool, d int) big.Int {
 		u - length of the prefix
 */
 func S3v3(n, k int, swap bool, d int) big.Int {
+	var res big.Int
+	columnsState().s3v3(&res, n, k, swap, d)
+	return res
+}
+
+// s3v3 is S3v3 writing its result in res.
+func (s *unrankState) s3v3(res *big.Int, n, k int, swap bool, d int) {
 	if d < 0 {
-		return *big.NewInt(0)
+		res.SetInt64(0)
+		return
 	}
 	if d == 0 {
 		if k-1 <= n && k-1 >= 0 {
 			if !swap {
-				return StirlingColumn1[n+1]
+				res.Set(&s.col1[n+1])
 			} else {
-				return StirlingColumn0[n+1]
+				res.Set(&s.col0[n+1])
 			}
+			return
 		}
-		return *big.NewInt(0)
+		res.SetInt64(0)
+		return
 	}
-	var res *big.Int
 	if !swap {
-		res = new(big.Int).Set(&StirlingColumn1[n+1])
+		res.Set(&s.col1[n+1])
 	} else {
-		res = new(big.Int).Set(&StirlingColumn0[n+1])
+		res.Set(&s.col0[n+1])
 	}
 
-	u := 0
-	b := big.NewInt(1)
-	pm1 := big.NewInt(-1)
-	for u < d {
-		b.Mul(b, big.NewInt(int64(d-u)))
-		u++
-		b.Div(b, big.NewInt(int64(u)))
-		var tmp *big.Int
+	row := s.binomials.Row(d, d)
+	tmp := s.scratch.get()
+	defer s.scratch.put(tmp)
+	for u := 1; u <= d; u++ {
+		b := &row[u]
 		if !swap {
-			tmp = new(big.Int).Mul(&StirlingColumn1[n+1-u], b)
+			tmp.Mul(&s.col1[n+1-u], b)
 		} else {
-			tmp = new(big.Int).Mul(&StirlingColumn0[n+1-u], b)
+			tmp.Mul(&s.col0[n+1-u], b)
+		}
+		// sign (-1)^u
+		if u%2 == 1 {
+			res.Sub(res, tmp)
+		} else {
+			res.Add(res, tmp)
 		}
-		tmp.Mul(tmp, pm1)
-		res.Add(res, tmp)
-		pm1.Neg(pm1)
 	}
-	return *res
 }
 
 /*
@@ -184,76 +242,73 @@ func S3v3(n, k int, swap bool, d int) big.Int {
 		u - length of the prefix
 */
 func S3v4(n, k int, swap bool, d int) big.Int {
+	var res big.Int
+	columnsState().s3v4(&res, n, k, swap, d)
+	return res
+}
+
+// s3v4 is S3v4 writing its result in res.
+func (s *unrankState) s3v4(res *big.Int, n, k int, swap bool, d int) {
 	if d < 0 {
-		return *big.NewInt(0)
+		res.SetInt64(0)
+		return
 	}
 	if d == 0 {
 		if k-1 <= n && k-1 >= 0 {
 			if !swap {
-				return StirlingColumn1[n+1]
+				res.Set(&s.col1[n+1])
 			} else {
-				return StirlingColumn0[n+1]
+				res.Set(&s.col0[n+1])
 			}
+			return
 		}
-		return *big.NewInt(0)
+		res.SetInt64(0)
+		return
 	}
-	var res big.Int
 	if d%2 == 1 {
 		if !swap {
-			res = *new(big.Int).Sub(&StirlingColumn1[n+1], &StirlingColumn1[n+1-d])
+			res.Sub(&s.col1[n+1], &s.col1[n+1-d])
 		} else {
-			res = *new(big.Int).Sub(&StirlingColumn0[n+1], &StirlingColumn0[n+1-d])
+			res.Sub(&s.col0[n+1], &s.col0[n+1-d])
 		}
 	} else {
 		if !swap {
-			res = *new(big.Int).Add(&StirlingColumn1[n+1], &StirlingColumn1[n+1-d])
+			res.Add(&s.col1[n+1], &s.col1[n+1-d])
 		} else {
-			res = *new(big.Int).Add(&StirlingColumn0[n+1], &StirlingColumn0[n+1-d])
+			res.Add(&s.col0[n+1], &s.col0[n+1-d])
 		}
 	}
-	u := 0
-	b := big.NewInt(1)
-	pm1 := big.NewInt(-1)
-	for u < min(d/2, n-k+1) {
-		b.Mul(b, big.NewInt(int64(d-u)))
-		u++
-		b.Div(b, big.NewInt(int64(u)))
+	row := s.binomials.Row(d, min(d/2, n-k+1))
+	s.sumS3(res, min(d/2, n-k+1), func(u int, tmp, aux *big.Int) {
+		b := &row[u]
 
 		if u < d/2 || (u == d/2 && d%2 == 1) {
 			if d%2 == 1 {
-				var tmp *big.Int
 				if !swap {
-					tmp = new(big.Int).Sub(&StirlingColumn1[n+1-u], &StirlingColumn1[n+1-d+u])
+					aux.Sub(&s.col1[n+1-u], &s.col1[n+1-d+u])
 				} else {
-					tmp = new(big.Int).Sub(&StirlingColumn0[n+1-u], &StirlingColumn0[n+1-d+u])
+					aux.Sub(&s.col0[n+1-u], &s.col0[n+1-d+u])
 				}
-				tmp.Mul(tmp, b)
-				tmp.Mul(tmp, pm1)
-				res.Add(&res, tmp)
 			} else {
-				var tmp *big.Int
 				if !swap {
-					tmp = new(big.Int).Add(&StirlingColumn1[n+1-u], &StirlingColumn1[n+1-d+u])
+					aux.Add(&s.col1[n+1-u], &s.col1[n+1-d+u])
 				} else {
-					tmp = new(big.Int).Add(&StirlingColumn0[n+1-u], &StirlingColumn0[n+1-d+u])
+					aux.Add(&s.col0[n+1-u], &s.col0[n+1-d+u])
 				}
-				tmp.Mul(tmp, b)
-				tmp.Mul(tmp, pm1)
-				res.Add(&res, tmp)
 			}
+			tmp.Mul(aux, b)
 		} else {
-			var tmp *big.Int
 			if !swap {
-				tmp = new(big.Int).Mul(&StirlingColumn1[n+1-u], b)
+				tmp.Mul(&s.col1[n+1-u], b)
 			} else {
-				tmp = new(big.Int).Mul(&StirlingColumn0[n+1-u], b)
+				tmp.Mul(&s.col0[n+1-u], b)
 			}
-			tmp.Mul(tmp, pm1)
-			res.Add(&res, tmp)
 		}
-		pm1.Neg(pm1)
-	}
-	return res
+		// sign (-1)^u
+		if u%2 == 1 {
+			tmp.Neg(tmp)
+		}
+	})
 }
 
 /*
@@ -264,10 +319,17 @@ func S3v4(n, k int, swap bool, d int) big.Int {
 		d - last element of the unranked prefix
 */
 func S3v5(n, k int, swap bool, d int) big.Int {
+	var res big.Int
+	columnsState().s3v5(&res, n, k, swap, d)
+	return res
+}
+
+// s3v5 is S3v5 writing its result in res.
+func (s *unrankState) s3v5(res *big.Int, n, k int, swap bool, d int) {
 	if 2*d < n {
-		return S3v4(n, k, swap, d)
+		s.s3v4(res, n, k, swap, d)
 	} else {
-		return S3v2(n, k, swap, d)
+		s.s3v2(res, n, k, swap, d)
 	}
 }
 
@@ -286,169 +348,241 @@ Example usage:
     fmt.Println(result) // Output: [[1 2 3] [4] [5]]
 */
 func UnrankDicho(n, k int, rank big.Int, whichS3 int) [][]int {
-	listK := make([]int64, 0)
-	blockSizes := make([]int64, 0)
-	blockTime := make([]int64, 0)
-	TimeTotal = 0
+	return types.LexicographicPermutationUnrank(n, newUnrankState(whichS3).unrankPositions(n, k, &rank, nil))
+}
+
+// UnrankStats is UnrankDicho also returning the times of the unrank, in particular those of the Stirling columns.
+func UnrankStats(n, k int, rank big.Int, whichS3 int) ([][]int, *Stats) {
+	stats := new(Stats)
+	return types.LexicographicPermutationUnrank(n, newUnrankState(whichS3).unrankPositions(n, k, &rank, stats)), stats
+}
+
+/*
+Return the blocks of the set partition of rank rank, each element being given by its position
+in the list of the elements that are not yet in a block, as expected by types.LexicographicPermutationUnrank.
+The times of the unrank are written in stats, which may be nil.
+*/
+func (s *unrankState) unrankPositions(n, k int, rank *big.Int, stats *Stats) [][]int {
+	res := make([][]int, 0, k)
+	s.eachPositions(n, k, rank, stats, func(block []int) bool {
+		res = append(res, block)
+		return true
+	})
+	return res
+}
+
+// eachPositions calls visit on the blocks of unrankPositions as soon as they are computed, until visit returns false.
+// The times of the unrank are written in stats, which may be nil.
+func (s *unrankState) eachPositions(n, k int, rank *big.Int, stats *Stats, visit func(block []int) bool) {
+	if stats == nil {
+		stats = new(Stats)
+	}
 	if k == 1 {
-		res := make([][]int, 0)
-		tmp := make([]int, 0)
-		for d := 1; d <= n; d++ {
-			tmp = append(tmp, d)
+		visit(make([]int, n))
+		return
+	}
+	if f := s.fixedWidthUnranker(n, k); f != nil {
+		stats.Width = f.Width()
+		for _, block := range f.UnrankPositions(rank) {
+			if !visit(block) {
+				return
+			}
 		}
-		res = append(res, tmp)
-		return res
+		return
 	}
 
-	n0 := n
-	res := make([][]int, 0)
-	r := *new(big.Int).Set(&rank)
-	chanRes := make(chan []big.Int)
-	startTime := time.Now().UnixMilli()
-	couple := *Stirling2Columns(n, k)
-	endTime := time.Now().UnixMilli()
-	WaitingTime = endTime - startTime
-	StirlingColumn0 = couple.Col0[:n]
-	StirlingColumn1 = couple.Col1
-
-	go computePreviousColumn(StirlingColumn0, n-1, k-1, chanRes)
+	r, acc := s.scratch.get(), s.scratch.get()
+	defer func() {
+		s.scratch.put(r)
+		s.scratch.put(acc)
+	}()
+	r.Set(rank)
+	startTime := time.Now().UnixMicro()
+	couple := *stirling2Columns(n, k, s.opts.Columns, s.opts.ColumnsWorkers)
+	stats.ColumnsTime = time.Now().UnixMicro() - startTime
+	s.col0 = couple.Col0[:n]
+	s.col1 = couple.Col1
+
+	// the pipeline is stopped when visit stops, or at the end, then the last columns are given back
+	defer func() {
+		s.scratch.putColumn(s.col0)
+		s.scratch.putColumn(s.col1)
+	}()
+	columns, stop := pipelineColumns(couple.Col0, k-1, s.opts.PipelineDepth, s.opts.PipelineWords, s.scratch)
+	defer stop()
 
 	swap := false
 
 	for k > 1 {
-		//fmt.Println(k)
-		listK = append(listK, int64(k))
-		var startTime int64
-		var endTime int64
-		startTime = time.Now().UnixMicro()
-		block, acc := optimizedBlockDicho(n, k, swap, r, whichS3)
-		endTime = time.Now().UnixMicro()
-		blockTime = append(blockTime, endTime-startTime)
-		res = append(res, block)
-		r.Sub(&r, &acc)
+		startTime := time.Now().UnixMicro()
+		block := s.optimizedBlockDicho(n, k, swap, r, acc)
+		stats.K = append(stats.K, int64(k))
+		stats.BlockTimes = append(stats.BlockTimes, time.Now().UnixMicro()-startTime)
+		stats.Sizes = append(stats.Sizes, int64(len(block)))
+		if !visit(block) {
+			return
+		}
+		r.Sub(r, acc)
 		n -= len(block)
 		k--
 
-		blockSizes = append(blockSizes, int64(len(block)))
+		startTime = time.Now().UnixMicro()
+		next := <-columns
+		stats.WaitTimes = append(stats.WaitTimes, time.Now().UnixMicro()-startTime)
+		stats.PreviousColumnK = append(stats.PreviousColumnK, next.k)
+		stats.PreviousColumnTimes = append(stats.PreviousColumnTimes, next.time)
+		// the column computed with the lines until n before the block
+		column := next.column[:n+len(block)]
+		// the replaced column, S(., k+1), is no longer read by the pipeline which is past S(., k-1)
 		if !swap {
-			startTime = time.Now().UnixMicro()
-			StirlingColumn1 = <-chanRes
-			endTime = time.Now().UnixMicro()
+			s.scratch.putColumn(s.col1)
+			s.col1 = column
 		} else {
-			startTime = time.Now().UnixMicro()
-			StirlingColumn0 = <-chanRes
-			endTime = time.Now().UnixMicro()
+			s.scratch.putColumn(s.col0)
+			s.col0 = column
 		}
-		TimeTotal += endTime - startTime
+		swap = !swap
+	}
+	visit(make([]int, n))
+}
+
+// fixedWidthUnranker returns the unranker on machine words of the partitions of [|1,n|] into k blocks, or nil when the option FixedWidth is false or S(n,k) >= 2^128.
+func (s *unrankState) fixedWidthUnranker(n, k int) *types.FixedWidthUnranker {
+	if !s.opts.FixedWidth {
+		return nil
+	}
+	if s.fixed == nil {
+		return types.NewFixedWidthUnranker(n, k)
+	}
+	return s.fixed.get(n, k)
+}
 
-		if k > 1 {
+// fixedWidthCache keeps the unrankers on machine words of an Unranker, nil ones included, for each n and k.
+type fixedWidthCache struct {
+	mu        sync.Mutex
+	unrankers map[[2]int]*types.FixedWidthUnranker
+}
 
-			if !swap {
-				go computePreviousColumn(StirlingColumn1, n-1, k-1, chanRes)
-			} else {
-				go computePreviousColumn(StirlingColumn0, n-1, k-1, chanRes)
-			}
+func (c *fixedWidthCache) get(n, k int) *types.FixedWidthUnranker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f, ok := c.unrankers[[2]int{n, k}]
+	if !ok {
+		if c.unrankers == nil {
+			c.unrankers = make(map[[2]int]*types.FixedWidthUnranker)
 		}
-		swap = !swap
+		f = types.NewFixedWidthUnranker(n, k)
+		c.unrankers[[2]int{n, k}] = f
 	}
-	res = append(res, make([]int, n))
-	res = lexicographicPermutationUnrank(n0, res)
-	return res
+	return f
+}
+
+// pipelineColumn is a column S(., k-1) sent by pipelineColumns, with k and the time of its computation in μs.
+type pipelineColumn struct {
+	column  []big.Int
+	k, time int64
 }
 
-func lexicographicPermutationUnrank(n int, Pos [][]int) [][]int {
-	L := make([]int, n)
-	for i := 0; i < n; i++ {
-		L[i] = i + 1
-	}
-	var P [][]int
-	for b := 0; b < len(Pos); b++ {
-		p := []int{}
-		for _, i := range Pos[b] {
-			p = append(p, L[i])
-			L = append(L[:i], L[i+1:]...)
+/*
+Send on the returned channel the columns S(., k-1), ..., S(., 0), computed in a goroutine each one from the
+previous one starting from the column S(., k), the columns being one line shorter each time. The goroutine
+keeps at most depth columns ahead of the receiver, fewer when they would hold more than words
+machine words, and writes nothing else than the columns it takes from pool, where the receiver may give
+back the columns it has replaced. The returned function stops it and waits for its end.
+*/
+func pipelineColumns(column []big.Int, k, depth, words int, pool *bigPool) (<-chan pipelineColumn, func()) {
+	size := 1
+	for i := range column {
+		size += len(column[i].Bits())
+	}
+	depth = max(1, min(depth, words/size))
+	// the column blocked on the send is also ahead of the receiver
+	res := make(chan pipelineColumn, depth-1)
+	stop, ended := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(ended)
+		for ; k >= 1; k-- {
+			startTime := time.Now().UnixMicro()
+			column = computePreviousColumn(pool.getColumn(len(column)-1), column, len(column)-2, k)
+			select {
+			case res <- pipelineColumn{column: column, k: int64(k), time: time.Now().UnixMicro() - startTime}:
+			case <-stop:
+				return
+			}
 		}
-		P = append(P, p)
+	}()
+	return res, func() {
+		close(stop)
+		<-ended
 	}
-	return P
 }
 
-func computePreviousColumn(column []big.Int, n, k int, resultChan chan []big.Int) {
-	TimePreviousColumnWithK = append(TimePreviousColumnWithK, int64(k))
-	startTime := time.Now().UnixMicro()
-	if k == 1 {
-		res := make([]big.Int, n+1)
-		res[0] = *big.NewInt(1)
-		resultChan <- res
-		return
+// computePreviousColumn returns S(., k-1) until the line n from the column S(., k), the line n being left at 0.
+// The result is written in dst when it is long enough.
+func computePreviousColumn(dst, column []big.Int, n, k int) []big.Int {
+	if k > 2 {
+		return types.PreviousColumn(dst, column, n, int64(k))
 	}
-	if k == 2 {
-		res := make([]big.Int, n+1)
-		res[0] = *big.NewInt(0)
-		for i := 1; i < len(res); i++ {
-			res[i] = *big.NewInt(1)
-		}
-		resultChan <- res
-		return
+	if cap(dst) < n+1 {
+		dst = make([]big.Int, n+1)
 	}
-	res := make([]big.Int, n+1)
-	res[0] = *big.NewInt(0)
-	for i := 1; i < n+1; i++ {
-		res[i-1].Sub(&column[i], big.NewInt(0).Mul(big.NewInt(int64(k)), &column[i-1]))
+	dst = dst[:n+1]
+	// S(i, 0) = 0 and S(i, 1) = 1 for i > 0
+	dst[0].SetInt64(int64(2 - k))
+	for i := 1; i < len(dst); i++ {
+		dst[i].SetInt64(int64(k - 1))
 	}
-	endTime := time.Now().UnixMicro()
-	TimePreviousColumn = append(TimePreviousColumn, endTime-startTime)
-	resultChan <- res
-
+	return dst
 }
 
-func optimizedBlockDicho(n, k int, swap bool, rank big.Int, whichS3 int) ([]int, big.Int) {
+// optimizedBlockDicho returns the positions of the next block, and writes in acc the number of set partitions before its first one.
+func (s *unrankState) optimizedBlockDicho(n, k int, swap bool, rank *big.Int, acc *big.Int) []int {
 	res := make([]int, 1)
-	var acc *big.Int
 	if !swap {
-		acc = new(big.Int).Set(&StirlingColumn0[n-1])
+		acc.Set(&s.col0[n-1])
 	} else {
-		acc = new(big.Int).Set(&StirlingColumn1[n-1])
+		acc.Set(&s.col1[n-1])
 	}
 
 	if rank.Cmp(acc) < 0 {
-		return res, *big.NewInt(0)
+		acc.SetInt64(0)
+		return res
 	}
+	s3, tmp, middleRank := s.scratch.get(), s.scratch.get(), s.scratch.get()
+	defer func() {
+		s.scratch.put(s3)
+		s.scratch.put(tmp)
+		s.scratch.put(middleRank)
+	}()
 	d0 := 1
 	position := 2
 	limitMin := 2
 	limitMax := n
 	completed := false
 	for !completed {
-		s3 := vs3[whichS3](n+1-position, k, swap, d0+1-position)
+		vs3[s.opts.WhichS3](s, s3, n+1-position, k, swap, d0+1-position)
 
-		tmp := new(big.Int).Sub(&rank, &s3)
+		tmp.Sub(rank, s3)
 		tmp.Sub(tmp, acc)
 
-		var limitMiddle int
-		for limitMin < limitMax {
-			limitMiddle = (limitMin + limitMax) / 2
-			tmpS3 := vs3[whichS3](n+1-position, k, swap, limitMiddle+1-position)
-			tmpS3 = *tmpS3.Neg(&tmpS3)
-			if tmp.Cmp(&tmpS3) >= 0 {
-				limitMin = limitMiddle + 1
-			} else {
-				limitMax = limitMiddle
-			}
-		}
-		limitMiddle = limitMin
-		tmp2S3 := vs3[whichS3](n+1-position, k, swap, limitMiddle-position)
-		middleRank := new(big.Int).Sub(&s3, &tmp2S3)
-		middleRank.Add(middleRank, acc)
+		limitMiddle := searchBoundary(s.opts.SearchFanOut, limitMin, limitMax, func(middle int) bool {
+			tmpS3 := s.scratch.get()
+			defer s.scratch.put(tmpS3)
+			vs3[s.opts.WhichS3](s, tmpS3, n+1-position, k, swap, middle+1-position)
+			tmpS3.Neg(tmpS3)
+			return tmp.Cmp(tmpS3) >= 0
+		})
+		vs3[s.opts.WhichS3](s, middleRank, n+1-position, k, swap, limitMiddle-position)
+		middleRank.Sub(s3, middleRank)
+		acc.Add(acc, middleRank)
 		res = append(res, limitMiddle-1-len(res))
-		acc = middleRank
-		var stirling big.Int
+		var stirling *big.Int
 		if !swap {
-			stirling = StirlingColumn0[n-position]
+			stirling = &s.col0[n-position]
 		} else {
-			stirling = StirlingColumn1[n-position]
+			stirling = &s.col1[n-position]
 		}
-		toCompare := new(big.Int).Add(&stirling, acc)
+		toCompare := middleRank.Add(stirling, acc)
 		if rank.Cmp(toCompare) < 0 {
 			completed = true
 		} else {
@@ -456,14 +590,27 @@ func optimizedBlockDicho(n, k int, swap bool, rank big.Int, whichS3 int) ([]int,
 			d0 = limitMiddle
 			limitMin = d0 + 1
 			limitMax = n
-			acc.Add(acc, &stirling)
+			acc.Add(acc, stirling)
 		}
 	}
-	return res, *acc
+	return res
 }
 
-// Return the k and the k-1th stirling triangle collumn until the line n.
+// Return the k and the k-1th stirling triangle collumn until the line n, with the method Columns.
 func Stirling2Columns(n, k int) *types.CoupleColumns {
+	return stirling2Columns(n, k, Columns, ColumnsWorkers)
+}
+
+// stirling2Columns is Stirling2Columns with the method method, on workers goroutines for the explicit one.
+func stirling2Columns(n, k int, method ColumnsMethod, workers int) *types.CoupleColumns {
+	if k > 1 && (method == ExplicitColumns || (method == AutoColumns && explicitColumnsCheaper(n, k, workers))) {
+		return Stirling2ColumnsExplicit(n, k, workers)
+	}
+	return Stirling2ColumnsRecurrence(n, k)
+}
+
+// Stirling2ColumnsRecurrence is Stirling2Columns with the recurrence of the Stirling numbers.
+func Stirling2ColumnsRecurrence(n, k int) *types.CoupleColumns {
 	// renvoie 2 colonnes de Stirling, k-1 et k jusqu'aux lignes n et n
 	// on suppose k >= 1
 	// il faut n-k+1 valeurs dans chaque colonne
@@ -471,7 +618,7 @@ func Stirling2Columns(n, k int) *types.CoupleColumns {
 		c0 := make([]big.Int, n+1)
 		c1 := make([]big.Int, n+1)
 		c0[0] = *big.NewInt(1)
-		for i := 1; i < n; i++ {
+		for i := 1; i <= n; i++ {
 			c1[i] = *big.NewInt(1)
 		}
 		couple := types.CoupleColumns{Col0: c0, Col1: c1}
@@ -485,14 +632,18 @@ func Stirling2Columns(n, k int) *types.CoupleColumns {
 	prev[0] = big.NewInt(0)
 
 	for j := 2; j < k+1; j++ {
+		bj := big.NewInt(int64(j))
 		if j%2 == 0 {
 			curr[j-2] = big.NewInt(0)
 			curr[j-1] = big.NewInt(0)
 			curr[j] = big.NewInt(1)
 
-			for i := j + 1; i < n-k+1+j; i++ { // on peut améliorer borne max avec min...
-				curr[i] = big.NewInt(0)
-				curr[i].Mul(big.NewInt(int64(j)), curr[i-1])
+			// the column k-1 also needs its line n
+			for i := j + 1; i <= min(n, n-k+1+j); i++ {
+				if curr[i] == nil {
+					curr[i] = new(big.Int)
+				}
+				curr[i].Mul(bj, curr[i-1])
 				curr[i].Add(curr[i], prev[i-1])
 			}
 		} else {
@@ -500,8 +651,9 @@ func Stirling2Columns(n, k int) *types.CoupleColumns {
 			prev[j-1] = big.NewInt(0)
 			prev[j] = big.NewInt(1)
 
-			for i := j + 1; i < n-k+1+j; i++ { // on peut améliorer borne max avec min...
-				prev[i].Mul(big.NewInt(int64(j)), prev[i-1])
+			// the column k-1 also needs its line n
+			for i := j + 1; i <= min(n, n-k+1+j); i++ {
+				prev[i].Mul(bj, prev[i-1])
 				prev[i].Add(prev[i], curr[i-1])
 			}
 		}
@@ -510,21 +662,21 @@ func Stirling2Columns(n, k int) *types.CoupleColumns {
 	c0 := make([]big.Int, n+1)
 	c1 := make([]big.Int, n+1)
 
-	for i := 0; i < n; i++ {
+	for i := 0; i <= n; i++ {
 		if k%2 == 0 {
-			c0[i] = *big.NewInt(0).Set(prev[i])
-			c1[i] = *big.NewInt(0).Set(curr[i])
+			c0[i].Set(prev[i])
+			c1[i].Set(curr[i])
 		} else {
-			c0[i] = *big.NewInt(0).Set(curr[i])
-			c1[i] = *big.NewInt(0).Set(prev[i])
+			c0[i].Set(curr[i])
+			c1[i].Set(prev[i])
 		}
 	}
-	if k%2 == 0 {
-		c1[n] = *big.NewInt(0).Set(curr[n])
-	} else {
-		c1[n] = *big.NewInt(0).Set(prev[n])
-	}
 
 	couple := types.CoupleColumns{Col0: c0, Col1: c1}
 	return &couple
 }
+
+// UnrankDichoPartition is UnrankDicho returning a types.Partition.
+func UnrankDichoPartition(n, k int, rank big.Int, whichS3 int) types.Partition {
+	return types.NewPartition(UnrankDicho(n, k, rank, whichS3))
+}
@@ -0,0 +1,64 @@
+package parallelunranking
+
+import (
+	"math/big"
+	"math/rand"
+)
+
+// QuantileRank returns floor(q S(n,k)), the rank at the fraction q of the order, q in [0, 1], the rank 1 giving the last partition, or nil when S(n,k) = 0.
+func QuantileRank(n, k int, q *big.Rat) *big.Int {
+	c := stirling(n, k)
+	if c.Sign() == 0 {
+		return nil
+	}
+	res := new(big.Int).Mul(c, q.Num())
+	res.Quo(res, q.Denom())
+	if res.Sign() < 0 {
+		res.SetInt64(0)
+	}
+	if res.Cmp(c) >= 0 {
+		res.Sub(c, big.NewInt(1))
+	}
+	return res
+}
+
+//  Unrank set partition at a quantile of the lexicographic order.
+/*
+Return the set partition of [|1,n|] into k blocks of rank floor(q S(n,k)), computed exactly,
+q in [0, 1], or nil when there is none. A float64 quantile can be given with new(big.Rat).SetFloat64(q).
+
+Example usage:
+
+	result := parallelunranking.UnrankQuantile(5, 3, big.NewRat(1, 2), 4)
+	fmt.Println(result) // Output: [[1 2 5] [3] [4]]
+*/
+func UnrankQuantile(n, k int, q *big.Rat, whichS3 int) [][]int {
+	r := QuantileRank(n, k, q)
+	if r == nil {
+		return nil
+	}
+	return UnrankDicho(n, k, *r, whichS3)
+}
+
+//  Stratified sampling of set partitions.
+/*
+Split the ranks of the set partitions of [|1,n|] into k blocks in m strata of equal size (up to one),
+the stratum i holding the ranks floor(i S(n,k)/m) <= r < floor((i+1) S(n,k)/m), and draw one partition
+uniformly in each of them. The empty strata, when m > S(n,k), are skipped.
+*/
+func StratifiedSample(n, k, m int, rg *rand.Rand, whichS3 int) [][][]int {
+	c := stirling(n, k)
+	res := make([][][]int, 0, m)
+	lo := big.NewInt(0)
+	for i := 1; i <= m; i++ {
+		hi := new(big.Int).Mul(c, big.NewInt(int64(i)))
+		hi.Quo(hi, big.NewInt(int64(m)))
+		width := new(big.Int).Sub(hi, lo)
+		if width.Sign() > 0 {
+			r := new(big.Int).Rand(rg, width)
+			res = append(res, UnrankDicho(n, k, *r.Add(r, lo), whichS3))
+		}
+		lo = hi
+	}
+	return res
+}
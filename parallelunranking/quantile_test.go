@@ -0,0 +1,68 @@
+package parallelunranking
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestQuantile(t *testing.T) {
+	for n := 1; n <= 6; n++ {
+		for k := 1; k <= n; k++ {
+			count := stirling2(n, k)
+			for den := int64(1); den <= 7; den++ {
+				for num := int64(0); num <= den; num++ {
+					q := big.NewRat(num, den)
+					// the rank of the last partition at or below the fraction q
+					want := num * count / den
+					if want == count {
+						want--
+					}
+					if r := QuantileRank(n, k, q); r.Int64() != want {
+						t.Fatalf("QuantileRank(%d, %d, %v) = %v, want %d", n, k, q, r, want)
+					}
+					if got, p := UnrankQuantile(n, k, q, 3), UnrankDicho(n, k, *big.NewInt(want), 3); fmt.Sprint(got) != fmt.Sprint(p) {
+						t.Fatalf("UnrankQuantile(%d, %d, %v) = %v, want %v", n, k, q, got, p)
+					}
+				}
+			}
+		}
+	}
+	for _, c := range [][2]int{{3, 4}, {3, 0}, {0, 1}, {3, -1}} {
+		if r, p := QuantileRank(c[0], c[1], big.NewRat(1, 2)), UnrankQuantile(c[0], c[1], big.NewRat(1, 2), 4); r != nil || p != nil {
+			t.Fatalf("QuantileRank and UnrankQuantile(%d, %d, 1/2) = %v, %v, want nil", c[0], c[1], r, p)
+		}
+	}
+}
+
+func TestStratifiedSample(t *testing.T) {
+	rg := rand.New(rand.NewSource(1))
+	for n := 1; n <= 6; n++ {
+		for k := 1; k <= n; k++ {
+			count := stirling2(n, k)
+			for m := int64(1); m <= count+2; m++ {
+				sample := StratifiedSample(n, k, int(m), rg, 3)
+				want := m
+				if count < m {
+					want = count
+				}
+				if int64(len(sample)) != want {
+					t.Fatalf("StratifiedSample(%d, %d, %d) has %d partitions, want %d", n, k, m, len(sample), want)
+				}
+				// each partition lies in its own stratum, the empty ones being skipped
+				i := int64(0)
+				for _, p := range sample {
+					r := RankDicho(n, k, p).Int64()
+					for (i+1)*count/m <= r || (i+1)*count/m == i*count/m {
+						i++
+					}
+					if r < i*count/m {
+						t.Fatalf("StratifiedSample(%d, %d, %d) = %v, two partitions in one stratum", n, k, m, sample)
+					}
+					i++
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package parallelunranking
+
+import (
+	"math/big"
+
+	"github.com/AMAURYCU/setpartition_unrank/types"
+)
+
+// positionsToRGS labels the elements from the positions returned by unrankPositions.
+func positionsToRGS(n int, pos [][]int) []int {
+	res := make([]int, n)
+	for b, block := range types.LexicographicPermutationUnrank(n, pos) {
+		for _, x := range block {
+			res[x-1] = b + 1
+		}
+	}
+	return res
+}
+
+//  Unrank set partition lexicographicaly as a restricted growth string.
+/*
+This function takes the same arguments as UnrankDicho and returns the restricted growth string
+a_1...a_n of the set partition, a_x being the number (from 1) of the block holding x.
+
+Example usage:
+
+	result := parallelunranking.UnrankRGS(5, 3, *big.NewInt(10), 4)
+	fmt.Println(result) // Output: [1 1 1 2 3]
+*/
+func UnrankRGS(n, k int, rank big.Int, whichS3 int) []int {
+	return positionsToRGS(n, newUnrankState(whichS3).unrankPositions(n, k, &rank, nil))
+}
+
+/*
+The set partitions of [|1,n|] into k blocks that start with the i first blocks of rgs have the ranks
+lo[i] <= r < lo[i] + count[i], for i in [|0,blocks|], blocks < k. The block j is made of the elements x
+with rgs[x-1] = j. ok is false when the block j does not start with the smallest element left.
+*/
+func prefixRanks(n, k int, rgs []int, blocks int) (lo, count []big.Int, ok bool) {
+	lo = make([]big.Int, blocks+1)
+	count = make([]big.Int, blocks+1)
+	if k == 1 {
+		count[0].SetInt64(1)
+		return lo, count, true
+	}
+	couple := Stirling2Columns(n, k)
+	binomials := types.NewBinomialCache(types.DefaultBinomialCacheWords)
+	count[0].Set(&couple.Col1[n])
+	// column is S(., k-j) when the block j is ranked
+	column := couple.Col0
+	remaining := make([]int, n)
+	for i := range remaining {
+		remaining[i] = i + 1
+	}
+	for j := 1; j <= blocks; j++ {
+		if len(remaining) == 0 || rgs[remaining[0]-1] != j {
+			return lo, count, false
+		}
+		res := &lo[j]
+		res.Set(&lo[j-1])
+		s := len(remaining)
+		next := remaining[:0]
+		d, R := 1, s-1
+		for i, x := range remaining {
+			if rgs[x-1] != j {
+				next = append(next, x)
+				continue
+			}
+			if i == 0 {
+				continue
+			}
+			e := i + 1
+			res.Add(res, &column[R])
+			res.Add(res, types.Tail(column, s-d, R, 0, 0, binomials))
+			res.Sub(res, types.Tail(column, s-e+1, R, 0, 0, binomials))
+			d, R = e, R-1
+		}
+		remaining = next
+		count[j].Set(&column[len(remaining)])
+		if j < blocks {
+			column = computePreviousColumn(nil, column, len(remaining), k-j)
+		}
+	}
+	return lo, count, true
+}
+
+//  Rank set partition given as a restricted growth string lexicographicaly.
+/*
+This function is the inverse of UnrankRGS. The result is nil when rgs is not the restricted
+growth string of a set partition of [|1,n|] into k blocks.
+
+Example usage:
+
+	rank := parallelunranking.RankRGS(5, 3, []int{1, 1, 1, 2, 3})
+	fmt.Println(rank) // Output: 10
+*/
+func RankRGS(n, k int, rgs []int) *big.Int {
+	if len(rgs) != n || k < 1 {
+		return nil
+	}
+	blocks := 0
+	for _, a := range rgs {
+		if a < 1 || a > blocks+1 {
+			return nil
+		}
+		blocks = max(blocks, a)
+	}
+	if blocks != k {
+		return nil
+	}
+	if k == 1 {
+		return big.NewInt(0)
+	}
+	lo, _, _ := prefixRanks(n, k, rgs, k-1)
+	return &lo[k-1]
+}
+
+// Rank set partition lexicographicaly, the inverse of UnrankDicho, the blocks can be given in any order.
+// The result is nil when partition is not a set partition of [|1,n|] into k blocks.
+func RankDicho(n, k int, partition [][]int) *big.Int {
+	p := types.NewPartition(partition)
+	if p.Validate(n, k) != nil {
+		return nil
+	}
+	return RankRGS(n, k, p.RGS())
+}
@@ -0,0 +1,86 @@
+package parallelunranking
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/AMAURYCU/setpartition_unrank/types"
+)
+
+// stirling2 returns S(n,k) from the recurrence.
+func stirling2(n, k int) int64 {
+	if n == 0 || k == 0 {
+		if n == k {
+			return 1
+		}
+		return 0
+	}
+	return int64(k)*stirling2(n-1, k) + stirling2(n-1, k-1)
+}
+
+// less compares two lists of blocks lexicographicaly, element after element.
+func less(a, b [][]int) bool {
+	var x, y []int
+	for _, block := range a {
+		x = append(x, block...)
+		x = append(x, 0)
+	}
+	for _, block := range b {
+		y = append(y, block...)
+		y = append(y, 0)
+	}
+	for i := 0; i < len(x) && i < len(y); i++ {
+		if x[i] != y[i] {
+			return x[i] < y[i]
+		}
+	}
+	return len(x) < len(y)
+}
+
+func TestUnrankRGS(t *testing.T) {
+	for n := 1; n <= 7; n++ {
+		for k := 1; k <= n; k++ {
+			var previous [][]int
+			for i := int64(0); i < stirling2(n, k); i++ {
+				rank := *big.NewInt(i)
+				blocks := UnrankDicho(n, k, rank, 3)
+				for whichS3 := 0; whichS3 < len(vs3); whichS3++ {
+					rgs := UnrankRGS(n, k, rank, whichS3)
+					if fmt.Sprint(rgs) != fmt.Sprint(types.BlocksToRGS(blocks)) {
+						t.Fatalf("UnrankRGS(%d, %d, %d, %d) = %v, want the RGS of %v", n, k, i, whichS3, rgs, blocks)
+					}
+				}
+				// the ranks follow the lexicographic order of the lists of blocks
+				if previous != nil && !less(previous, blocks) {
+					t.Fatalf("UnrankDicho(%d, %d, %d) = %v is not after %v", n, k, i, blocks, previous)
+				}
+				previous = blocks
+				rgs := types.BlocksToRGS(blocks)
+				if r := RankRGS(n, k, rgs); r.Cmp(&rank) != 0 {
+					t.Fatalf("RankRGS(%d, %d, %v) = %v, want %d", n, k, rgs, r, i)
+				}
+				reversed := make([][]int, len(blocks))
+				for b, block := range blocks {
+					reversed[len(blocks)-1-b] = block
+				}
+				if r := RankDicho(n, k, reversed); r.Cmp(&rank) != 0 {
+					t.Fatalf("RankDicho(%d, %d, %v) = %v, want %d", n, k, reversed, r, i)
+				}
+			}
+		}
+	}
+}
+
+func TestRankInvalid(t *testing.T) {
+	for _, rgs := range [][]int{{2, 1, 1, 1}, {1, 1, 3, 2}, {1, 2}, {1, 2, 3, 2, 1}, {1, 0, 1, 2}, {1, 2, 1, 1}} {
+		if r := RankRGS(4, 3, rgs); r != nil {
+			t.Errorf("RankRGS(4, 3, %v) = %v, want nil", rgs, r)
+		}
+	}
+	for _, p := range [][][]int{{{1, 2}, {3, 4}}, {{1, 2}, {2, 3}, {4}}, {{1}, {3}, {4}}, {{1, 2}, {3}, {4}, {}}, {{1, 5}, {3}, {2, 4}}} {
+		if r := RankDicho(4, 3, p); r != nil {
+			t.Errorf("RankDicho(4, 3, %v) = %v, want nil", p, r)
+		}
+	}
+}
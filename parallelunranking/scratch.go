@@ -0,0 +1,61 @@
+package parallelunranking
+
+import (
+	"math/big"
+	"sync"
+)
+
+// bigPool lends big.Int buffers and columns of them, which keep their memory from one use to the next. A nil *bigPool allocates them.
+type bigPool struct {
+	mutex   sync.Mutex
+	free    []*big.Int
+	columns [][]big.Int
+}
+
+func (p *bigPool) get() *big.Int {
+	if p == nil {
+		return new(big.Int)
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if len(p.free) == 0 {
+		return new(big.Int)
+	}
+	x := p.free[len(p.free)-1]
+	p.free = p.free[:len(p.free)-1]
+	return x
+}
+
+func (p *bigPool) put(x *big.Int) {
+	if p != nil {
+		p.mutex.Lock()
+		p.free = append(p.free, x)
+		p.mutex.Unlock()
+	}
+}
+
+// getColumn returns a column of n big.Int, with the values left by its previous use.
+func (p *bigPool) getColumn(n int) []big.Int {
+	if p == nil {
+		return make([]big.Int, n)
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for i := len(p.columns) - 1; i >= 0; i-- {
+		if c := p.columns[i]; cap(c) >= n {
+			last := len(p.columns) - 1
+			p.columns[i] = p.columns[last]
+			p.columns = p.columns[:last]
+			return c[:n]
+		}
+	}
+	return make([]big.Int, n)
+}
+
+func (p *bigPool) putColumn(c []big.Int) {
+	if p != nil && cap(c) > 0 {
+		p.mutex.Lock()
+		p.columns = append(p.columns, c)
+		p.mutex.Unlock()
+	}
+}
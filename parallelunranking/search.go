@@ -0,0 +1,70 @@
+package parallelunranking
+
+import (
+	"math/big"
+)
+
+// stirling returns S(n, k), the number of set partitions of [|1,n|] into k blocks.
+func stirling(n, k int) *big.Int {
+	if k < 1 || k > n {
+		return big.NewInt(0)
+	}
+	if k == 1 {
+		return big.NewInt(1)
+	}
+	c := Stirling2Columns(n, k).Col1[n]
+	return &c
+}
+
+// Smallest rank in [lo, hi) whose partition satisfies pred, hi if there is none, pred being false then true.
+func searchRank(n, k int, lo, hi *big.Int, pred func(partition [][]int) bool) *big.Int {
+	lo = new(big.Int).Set(lo)
+	hi = new(big.Int).Set(hi)
+	mid := new(big.Int)
+	for lo.Cmp(hi) < 0 {
+		mid.Add(lo, hi)
+		mid.Rsh(mid, 1)
+		if pred(UnrankDicho(n, k, *mid, 4)) {
+			hi.Set(mid)
+		} else {
+			lo.Add(mid, big.NewInt(1))
+		}
+	}
+	return lo
+}
+
+//  First set partition satisfying a monotone predicate.
+/*
+pred must be monotone in the order of UnrankDicho : false on the ranks below some rank and true
+from this rank. Return this rank and its set partition of [|1,n|] into k blocks, with O(log S(n,k))
+calls to UnrankDicho, or nil, nil when pred is never true.
+
+Example usage:
+
+	// the first partition whose first block starts with 1 then an element >= 3
+	rank, p := parallelunranking.FirstSatisfying(5, 2, func(p [][]int) bool { return len(p[0]) > 1 && p[0][1] >= 3 })
+	fmt.Println(rank, p) // Output: 8 [[1 3] [2 4 5]]
+*/
+func FirstSatisfying(n, k int, pred func(partition [][]int) bool) (*big.Int, [][]int) {
+	c := stirling(n, k)
+	r := searchRank(n, k, big.NewInt(0), c, pred)
+	if r.Cmp(c) == 0 {
+		return nil, nil
+	}
+	return r, UnrankDicho(n, k, *r, 4)
+}
+
+//  Last set partition satisfying a monotone predicate.
+/*
+pred must be true on the ranks below some rank and false from this rank. Return the last rank
+where pred is true and its set partition, or nil, nil when pred is never true.
+*/
+func LastSatisfying(n, k int, pred func(partition [][]int) bool) (*big.Int, [][]int) {
+	c := stirling(n, k)
+	r := searchRank(n, k, big.NewInt(0), c, func(partition [][]int) bool { return !pred(partition) })
+	if r.Sign() == 0 {
+		return nil, nil
+	}
+	r.Sub(r, big.NewInt(1))
+	return r, UnrankDicho(n, k, *r, 4)
+}
@@ -0,0 +1,59 @@
+package parallelunranking
+
+import (
+	"sync"
+)
+
+// SearchFanOut is the number of intervals the binary search of optimizedBlockDicho splits its interval
+// into at each step, evaluating the SearchFanOut-1 midpoints concurrently, 2 or less for the sequential search.
+var SearchFanOut = 2
+
+/*
+Return the smallest m in [lo, hi) such that pred(m) is false, hi if there is none, pred being true then false.
+With fanOut > 2, the interval is split into fanOut intervals at each step and pred is evaluated concurrently on
+their midpoints, the result being the same as the sequential binary search. Concurrent calls to pred must not
+write to the same variables.
+*/
+func searchBoundary(fanOut, lo, hi int, pred func(m int) bool) int {
+	if fanOut <= 2 {
+		for lo < hi {
+			middle := (lo + hi) / 2
+			if pred(middle) {
+				lo = middle + 1
+			} else {
+				hi = middle
+			}
+		}
+		return lo
+	}
+	for lo < hi {
+		// the distinct points lo + j (hi-lo)/fanOut of [lo, hi), for j in [|1, fanOut-1|]
+		points := make([]int, 0, fanOut-1)
+		for j := 1; j < fanOut; j++ {
+			if p := lo + j*(hi-lo)/fanOut; len(points) == 0 || p > points[len(points)-1] {
+				points = append(points, p)
+			}
+		}
+		results := make([]bool, len(points))
+		var wg sync.WaitGroup
+		for i := range points {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i] = pred(points[i])
+			}(i)
+		}
+		wg.Wait()
+		i := 0
+		for i < len(points) && results[i] {
+			i++
+		}
+		if i > 0 {
+			lo = points[i-1] + 1
+		}
+		if i < len(points) {
+			hi = points[i]
+		}
+	}
+	return lo
+}
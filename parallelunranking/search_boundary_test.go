@@ -0,0 +1,61 @@
+package parallelunranking
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestSearchBoundary(t *testing.T) {
+	for fanOut := 1; fanOut <= 8; fanOut++ {
+		for lo := 0; lo <= 3; lo++ {
+			for hi := lo; hi <= lo+40; hi++ {
+				for boundary := lo; boundary <= hi; boundary++ {
+					var mutex sync.Mutex
+					calls := 0
+					got := searchBoundary(fanOut, lo, hi, func(m int) bool {
+						mutex.Lock()
+						calls++
+						mutex.Unlock()
+						if m < lo || m >= hi {
+							t.Errorf("searchBoundary(%d, %d, %d) calls pred(%d)", fanOut, lo, hi, m)
+						}
+						return m < boundary
+					})
+					if want := searchBoundary(2, lo, hi, func(m int) bool { return m < boundary }); got != boundary || want != boundary {
+						t.Fatalf("searchBoundary(%d, %d, %d) = %d, binary search %d, want %d", fanOut, lo, hi, got, want, boundary)
+					}
+					if calls > hi-lo {
+						t.Fatalf("searchBoundary(%d, %d, %d) calls pred %d times", fanOut, lo, hi, calls)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSearchFanOut(t *testing.T) {
+	rg := rand.New(rand.NewSource(1))
+	for _, c := range [][2]int{{30, 5}, {120, 10}, {120, 100}, {300, 40}} {
+		n, k := c[0], c[1]
+		count := Stirling2Columns(n, k).Col1[n]
+		ranks := []big.Int{*big.NewInt(0), *new(big.Int).Sub(&count, big.NewInt(1))}
+		for i := 0; i < 3; i++ {
+			var r big.Int
+			ranks = append(ranks, *r.Rand(rg, &count))
+		}
+		for _, fanOut := range []int{3, 4, 5, 8} {
+			opts := DefaultOptions()
+			opts.SearchFanOut, opts.FixedWidth = fanOut, false
+			u := NewUnranker(opts)
+			for i := range ranks {
+				want := UnrankDicho(n, k, ranks[i], 4)
+				if got := u.Unrank(n, k, &ranks[i]); fmt.Sprint(got) != fmt.Sprint(want) {
+					t.Fatalf("Unrank(%d, %d, %v) with fan-out %d = %v, want %v", n, k, &ranks[i], fanOut, got, want)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package parallelunranking
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestSatisfying(t *testing.T) {
+	for n := 1; n <= 6; n++ {
+		for k := 1; k <= n; k++ {
+			count := stirling2(n, k)
+			for threshold := int64(0); threshold <= count; threshold++ {
+				// true from the rank threshold, found by brute force
+				from := func(p [][]int) bool { return RankDicho(n, k, p).Int64() >= threshold }
+				r, p := FirstSatisfying(n, k, from)
+				if threshold == count {
+					if r != nil || p != nil {
+						t.Fatalf("FirstSatisfying(%d, %d, >= %d) = %v, %v, want nil", n, k, threshold, r, p)
+					}
+				} else if want := UnrankDicho(n, k, *big.NewInt(threshold), 3); r.Int64() != threshold || fmt.Sprint(p) != fmt.Sprint(want) {
+					t.Fatalf("FirstSatisfying(%d, %d, >= %d) = %v, %v, want %d, %v", n, k, threshold, r, p, threshold, want)
+				}
+				r, p = LastSatisfying(n, k, func(p [][]int) bool { return !from(p) })
+				if threshold == 0 {
+					if r != nil || p != nil {
+						t.Fatalf("LastSatisfying(%d, %d, < %d) = %v, %v, want nil", n, k, threshold, r, p)
+					}
+				} else if want := UnrankDicho(n, k, *big.NewInt(threshold - 1), 3); r.Int64() != threshold-1 || fmt.Sprint(p) != fmt.Sprint(want) {
+					t.Fatalf("LastSatisfying(%d, %d, < %d) = %v, %v, want %d, %v", n, k, threshold, r, p, threshold-1, want)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package parallelunranking
+
+// Stats of an unrank, the times being in μs.
+type Stats struct {
+	// the number of bits of the machine words of the unrank, 64 or 128, or 0 for math/big, the times below being then empty
+	Width int
+	// time of the computation of the first two Stirling columns
+	ColumnsTime int64
+	// for each block but the last one : the number of blocks left, the size of the block,
+	// the time of its binary search, and the time waited for the next Stirling column
+	K, Sizes, BlockTimes, WaitTimes []int64
+	// for each of these Stirling columns S(., k-1) : k and the time of its computation ahead of the blocks
+	PreviousColumnK, PreviousColumnTimes []int64
+}
+
+// TotalWait returns the time waited for the Stirling columns computed ahead.
+func (s *Stats) TotalWait() int64 {
+	total := int64(0)
+	for _, t := range s.WaitTimes {
+		total += t
+	}
+	return total
+}
@@ -0,0 +1,28 @@
+package parallelunranking
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestUnrankStats(t *testing.T) {
+	n, k := 200, 30
+	c := Stirling2Columns(n, k).Col1[n]
+	rank := new(big.Int).Rsh(&c, 1)
+	p, stats := UnrankStats(n, k, *rank, 4)
+	if want := UnrankDicho(n, k, *rank, 4); fmt.Sprint(p) != fmt.Sprint(want) {
+		t.Fatalf("UnrankStats(%d, %d, %v) = %v, want %v", n, k, rank, p, want)
+	}
+	for _, times := range [][]int64{stats.K, stats.Sizes, stats.BlockTimes, stats.WaitTimes, stats.PreviousColumnK, stats.PreviousColumnTimes} {
+		if len(times) != k-1 {
+			t.Fatalf("UnrankStats(%d, %d, %v) gives %d times, want %d", n, k, rank, len(times), k-1)
+		}
+	}
+	for i, j := range stats.PreviousColumnK {
+		// the column S(., j-1) is received after the block of j+1 blocks left
+		if j != stats.K[i]-1 {
+			t.Fatalf("UnrankStats(%d, %d, %v) receives the column of %d after the block of %d", n, k, rank, j, stats.K[i])
+		}
+	}
+}
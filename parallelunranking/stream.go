@@ -0,0 +1,40 @@
+package parallelunranking
+
+import (
+	"math/big"
+
+	"github.com/AMAURYCU/setpartition_unrank/types"
+)
+
+//  Unrank set partition lexicographicaly block by block.
+/*
+This function takes the arguments of UnrankDicho and a channel done. It returns a channel that
+receives the blocks of the set partition, with their elements, as soon as each one is computed,
+and is closed after the last block. Closing done stops the computation early, the channel being
+then closed without the blocks left.
+
+Example usage:
+
+	done := make(chan struct{})
+	for block := range parallelunranking.UnrankDichoStream(5, 3, *big.NewInt(10), 4, done) {
+		fmt.Println(block) // Output: [1 2 3], then [4], then [5]
+	}
+*/
+func UnrankDichoStream(n, k int, rank big.Int, whichS3 int, done <-chan struct{}) <-chan []int {
+	res := make(chan []int)
+	// the options are read before the goroutine starts
+	state := newUnrankState(whichS3)
+	go func() {
+		defer close(res)
+		remaining := types.NewRemaining(n)
+		state.eachPositions(n, k, &rank, nil, func(pos []int) bool {
+			select {
+			case res <- remaining.Take(pos):
+				return true
+			case <-done:
+				return false
+			}
+		})
+	}()
+	return res
+}
@@ -0,0 +1,72 @@
+package parallelunranking
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestUnrankDichoStream(t *testing.T) {
+	for n := 1; n <= 7; n++ {
+		for k := 1; k <= n; k++ {
+			for i := int64(0); i < stirling2(n, k); i++ {
+				want := UnrankDicho(n, k, *big.NewInt(i), 3)
+				got := make([][]int, 0, k)
+				for block := range UnrankDichoStream(n, k, *big.NewInt(i), 3, make(chan struct{})) {
+					got = append(got, block)
+				}
+				if fmt.Sprint(got) != fmt.Sprint(want) {
+					t.Fatalf("UnrankDichoStream(%d, %d, %d) = %v, want %v", n, k, i, got, want)
+				}
+			}
+		}
+	}
+}
+
+// TestUnrankDichoStreamInterleaved unranks other partitions while the stream is running.
+func TestUnrankDichoStreamInterleaved(t *testing.T) {
+	rg := rand.New(rand.NewSource(1))
+	n, k := 200, 30
+	c := Stirling2Columns(n, k).Col1[n]
+	other := Stirling2Columns(n, k-3).Col1[n]
+	for _, whichS3 := range []int{1, 3, 4} {
+		var rank, r big.Int
+		rank.Rand(rg, &c)
+		want := UnrankDicho(n, k, rank, whichS3)
+		got := make([][]int, 0, k)
+		for block := range UnrankDichoStream(n, k, rank, whichS3, make(chan struct{})) {
+			got = append(got, block)
+			r.Rand(rg, &other)
+			if p := UnrankDicho(n, k-3, r, whichS3); len(p) != k-3 {
+				t.Fatalf("UnrankDicho(%d, %d, %v) = %v", n, k-3, &r, p)
+			}
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("UnrankDichoStream(%d, %d, %v, %d) = %v, want %v", n, k, &rank, whichS3, got, want)
+		}
+	}
+}
+
+func TestUnrankDichoStreamDone(t *testing.T) {
+	n, k := 200, 30
+	c := Stirling2Columns(n, k).Col1[n]
+	rank := new(big.Int).Rsh(&c, 1)
+	want := UnrankDicho(n, k, *rank, 4)
+	done := make(chan struct{})
+	blocks := UnrankDichoStream(n, k, *rank, 4, done)
+	for i := 0; i < 3; i++ {
+		if block := <-blocks; fmt.Sprint(block) != fmt.Sprint(want[i]) {
+			t.Fatalf("UnrankDichoStream(%d, %d, %v) gives %v, want %v", n, k, rank, block, want[i])
+		}
+	}
+	close(done)
+	// the channel is closed, the blocks still received being the next ones
+	i := 3
+	for block := range blocks {
+		if fmt.Sprint(block) != fmt.Sprint(want[i]) {
+			t.Fatalf("UnrankDichoStream(%d, %d, %v) gives %v after done, want %v", n, k, rank, block, want[i])
+		}
+		i++
+	}
+}
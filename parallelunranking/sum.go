@@ -0,0 +1,55 @@
+package parallelunranking
+
+import (
+	"math/big"
+	"runtime"
+	"sync"
+)
+
+// S3Workers is the number of goroutines sharing the sums of S3v2 and S3v4, 1 keeps them sequential.
+var S3Workers = runtime.NumCPU()
+
+// S3ParallelThreshold is the number of terms from which the sums of S3v2 and S3v4 are shared between S3Workers goroutines.
+var S3ParallelThreshold = DefaultS3ParallelThreshold
+
+// DefaultS3ParallelThreshold is the default value of S3ParallelThreshold, see statistic.ParallelS3Benchmark.
+const DefaultS3ParallelThreshold = 512
+
+/*
+Add to res the terms u = 1, ..., l, term setting tmp to the term u, with aux as a buffer. Above the option
+S3ParallelThreshold terms, the range of u is split in S3Workers contiguous chunks summed concurrently, then
+the partial sums are added, which gives the same result. Each chunk has its own tmp and aux, the other
+values reached by term being read by all the chunks at once.
+*/
+func (s *unrankState) sumS3(res *big.Int, l int, term func(u int, tmp, aux *big.Int)) {
+	workers := min(s.opts.S3Workers, l)
+	if l < s.opts.S3ParallelThreshold || workers <= 1 {
+		tmp, aux := s.scratch.get(), s.scratch.get()
+		defer func() {
+			s.scratch.put(tmp)
+			s.scratch.put(aux)
+		}()
+		for u := 1; u <= l; u++ {
+			term(u, tmp, aux)
+			res.Add(res, tmp)
+		}
+		return
+	}
+	partial := make([]big.Int, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			tmp, aux := new(big.Int), new(big.Int)
+			for u := 1 + w*l/workers; u <= (w+1)*l/workers; u++ {
+				term(u, tmp, aux)
+				partial[w].Add(&partial[w], tmp)
+			}
+		}(w)
+	}
+	wg.Wait()
+	for w := range partial {
+		res.Add(res, &partial[w])
+	}
+}
@@ -0,0 +1,119 @@
+package parallelunranking
+
+import (
+	"math/big"
+	"runtime"
+
+	"github.com/AMAURYCU/setpartition_unrank/types"
+)
+
+// Options of an Unranker.
+type Options struct {
+	// the version of the S3 formula, in [|0,4|]
+	WhichS3 int
+	// the number of goroutines sharing the sums of S3v2 and S3v4, 1 keeps them sequential
+	S3Workers int
+	// the number of terms from which these sums are shared, 0 giving DefaultS3ParallelThreshold
+	S3ParallelThreshold int
+	// the number of intervals of each step of the binary search on the elements of a block,
+	// whose midpoints are evaluated concurrently, 2 for the sequential binary search
+	SearchFanOut int
+	// the number of previous Stirling columns computed ahead, and the machine words they may hold
+	PipelineDepth, PipelineWords int
+	// unrank on machine words when S(n,k) < 2^128
+	FixedWidth bool
+	// the method of the first Stirling columns, and the goroutines of the explicit one
+	Columns        ColumnsMethod
+	ColumnsWorkers int
+}
+
+// DefaultOptions returns the fastest S3 formula, shared between all the cores for the large sums, the sequential binary search, two Stirling columns computed ahead, machine words when they are enough, and the cheapest method for the first Stirling columns on all the cores.
+func DefaultOptions() Options {
+	return Options{WhichS3: 4, S3Workers: runtime.NumCPU(), S3ParallelThreshold: DefaultS3ParallelThreshold, SearchFanOut: 2, PipelineDepth: DefaultPipelineDepth, PipelineWords: DefaultPipelineWords, FixedWidth: true, Columns: AutoColumns, ColumnsWorkers: runtime.NumCPU()}
+}
+
+// Unranker unranks set partitions lexicographicaly with fixed Options.
+type Unranker struct {
+	opts Options
+	// the big.Int buffers, the binomial coefficients and the unrankers on machine words of the unranks, kept from one to the next
+	scratch   bigPool
+	binomials *types.BinomialCache
+	fixed     fixedWidthCache
+}
+
+// NewUnranker returns an Unranker with the options opts.
+func NewUnranker(opts Options) *Unranker {
+	if opts.S3Workers < 1 {
+		opts.S3Workers = 1
+	}
+	if opts.S3ParallelThreshold == 0 {
+		opts.S3ParallelThreshold = DefaultS3ParallelThreshold
+	}
+	if opts.SearchFanOut < 2 {
+		opts.SearchFanOut = 2
+	}
+	if opts.PipelineDepth < 1 {
+		opts.PipelineDepth = 1
+	}
+	if opts.ColumnsWorkers < 1 {
+		opts.ColumnsWorkers = 1
+	}
+	return &Unranker{opts: opts, binomials: types.NewBinomialCache(BinomialCacheWords)}
+}
+
+// state returns the state of an unrank of u, with its options, which shares the binomial coefficients, the big.Int buffers and the unrankers on machine words of u.
+func (u *Unranker) state() *unrankState {
+	return &unrankState{opts: u.opts, binomials: u.binomials, scratch: &u.scratch, fixed: &u.fixed}
+}
+
+// Options returns the options of u.
+func (u *Unranker) Options() Options {
+	return u.opts
+}
+
+//  Unrank set partition lexicographicaly with the options of the Unranker.
+/*
+Same as UnrankDicho, several goroutines may call it on the same or different Unrankers, the
+unranks running at the same time, each one with the options of its Unranker.
+
+Example usage:
+
+	u := parallelunranking.NewUnranker(parallelunranking.DefaultOptions())
+	fmt.Println(u.Unrank(5, 3, big.NewInt(10))) // Output: [[1 2 3] [4] [5]]
+*/
+func (u *Unranker) Unrank(n, k int, rank *big.Int) [][]int {
+	return types.LexicographicPermutationUnrank(n, u.state().unrankPositions(n, k, rank, nil))
+}
+
+//  Unrank set partition lexicographicaly into the blocks of dst.
+/*
+Same as Unrank, the blocks of the result being those of dst when they are large enough, so that
+unranking again and again into the previous result allocates little besides the big.Int buffers
+kept by the Unranker.
+
+Example usage:
+
+	u := parallelunranking.NewUnranker(parallelunranking.DefaultOptions())
+	var p [][]int
+	for r := int64(0); r < 3; r++ {
+		p = u.UnrankInto(p, 5, 3, big.NewInt(r))
+		fmt.Println(p) // Output: [[1] [2] [3 4 5]], then [[1] [2 3] [4 5]], then [[1] [2 3 4] [5]]
+	}
+*/
+func (u *Unranker) UnrankInto(dst [][]int, n, k int, rank *big.Int) [][]int {
+	return types.LexicographicPermutationUnrankInto(dst, n, u.state().unrankPositions(n, k, rank, nil))
+}
+
+// UnrankStats is Unrank also returning the times of the unrank, in particular the time waited for the Stirling columns after each block.
+func (u *Unranker) UnrankStats(n, k int, rank *big.Int) ([][]int, *Stats) {
+	stats := new(Stats)
+	return types.LexicographicPermutationUnrank(n, u.state().unrankPositions(n, k, rank, stats)), stats
+}
+
+// Width returns the number of bits of the machine words used by u to unrank the set partitions of [|1,n|] into k blocks, 64 or 128, or 0 for math/big.
+func (u *Unranker) Width(n, k int) int {
+	if f := u.state().fixedWidthUnranker(n, k); f != nil {
+		return f.Width()
+	}
+	return 0
+}
@@ -0,0 +1,103 @@
+package parallelunranking
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+// TestUnrankerConcurrent runs Unrankers with different options and UnrankDicho at the same time.
+func TestUnrankerConcurrent(t *testing.T) {
+	rg := rand.New(rand.NewSource(1))
+	n, k := 150, 20
+	c := Stirling2Columns(n, k).Col1[n]
+	ranks := make([]big.Int, 8)
+	want := make([]string, len(ranks))
+	for i := range ranks {
+		ranks[i].Rand(rg, &c)
+		want[i] = fmt.Sprint(UnrankDicho(n, k, ranks[i], 4))
+	}
+	unrankers := make([]func(rank *big.Int) [][]int, 0)
+	for _, whichS3 := range []int{1, 3, 4} {
+		opts := DefaultOptions()
+		opts.WhichS3, opts.S3Workers, opts.S3ParallelThreshold = whichS3, 3, 1
+		opts.SearchFanOut, opts.PipelineDepth, opts.Columns = 4, 3, ExplicitColumns
+		u := NewUnranker(opts)
+		unrankers = append(unrankers, func(rank *big.Int) [][]int { return u.Unrank(n, k, rank) })
+		var p [][]int
+		var mutex sync.Mutex
+		unrankers = append(unrankers, func(rank *big.Int) [][]int {
+			mutex.Lock()
+			defer mutex.Unlock()
+			p = u.UnrankInto(p, n, k, rank)
+			return p
+		})
+		unrankers = append(unrankers, func(rank *big.Int) [][]int { return UnrankDicho(n, k, *rank, whichS3) })
+	}
+	var wg sync.WaitGroup
+	for _, unrank := range unrankers {
+		wg.Add(1)
+		go func(unrank func(rank *big.Int) [][]int) {
+			defer wg.Done()
+			for i := range ranks {
+				if got := fmt.Sprint(unrank(&ranks[i])); got != want[i] {
+					t.Errorf("unrank of %v = %v, want %v", &ranks[i], got, want[i])
+				}
+			}
+		}(unrank)
+	}
+	wg.Wait()
+}
+
+// TestFixedWidth compares the unranks on machine words with the ones on math/big, in both widths.
+func TestFixedWidth(t *testing.T) {
+	rg := rand.New(rand.NewSource(2))
+	opts := DefaultOptions()
+	fixed := NewUnranker(opts)
+	opts.FixedWidth = false
+	bigInts := NewUnranker(opts)
+	for _, c := range []struct{ n, k, width int }{{6, 3, 64}, {9, 4, 64}, {25, 12, 64}, {40, 5, 128}, {30, 15, 128}, {45, 20, 0}, {120, 60, 0}} {
+		if w := fixed.Width(c.n, c.k); w != c.width {
+			t.Fatalf("Width(%d, %d) = %d, want %d", c.n, c.k, w, c.width)
+		}
+		if w := bigInts.Width(c.n, c.k); w != 0 {
+			t.Fatalf("Width(%d, %d) = %d without FixedWidth", c.n, c.k, w)
+		}
+		count := Stirling2Columns(c.n, c.k).Col1[c.n]
+		ranks := make([]*big.Int, 0)
+		if count.IsInt64() && count.Int64() <= 2000 {
+			for i := int64(0); i < count.Int64(); i++ {
+				ranks = append(ranks, big.NewInt(i))
+			}
+		} else {
+			last := new(big.Int).Sub(&count, big.NewInt(1))
+			ranks = append(ranks, big.NewInt(0), last)
+			for i := 0; i < 50; i++ {
+				ranks = append(ranks, new(big.Int).Rand(rg, &count))
+			}
+		}
+		for _, r := range ranks {
+			got, want := fixed.Unrank(c.n, c.k, r), bigInts.Unrank(c.n, c.k, r)
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Fatalf("Unrank(%d, %d, %v) = %v on %d bits, %v on math/big", c.n, c.k, r, got, c.width, want)
+			}
+		}
+	}
+}
+
+// TestUnrankerColumnsReused checks that the Stirling columns of the blocks come from the buffers of the Unranker.
+func TestUnrankerColumnsReused(t *testing.T) {
+	n, k := 300, 40
+	opts := DefaultOptions()
+	opts.FixedWidth = false
+	u := NewUnranker(opts)
+	rank := new(big.Int).Rsh(&Stirling2Columns(n, k).Col1[n], 1)
+	var p [][]int
+	p = u.UnrankInto(p, n, k, rank)
+	// a fresh column for each block would make n allocations per block
+	if allocs := testing.AllocsPerRun(5, func() { p = u.UnrankInto(p, n, k, rank) }); allocs > float64(n*k*2/3) {
+		t.Errorf("UnrankInto(%d, %d) makes %v allocations", n, k, allocs)
+	}
+}
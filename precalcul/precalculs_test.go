@@ -0,0 +1,41 @@
+package precalcul
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/AMAURYCU/setpartition_unrank/parallelunranking"
+)
+
+// TestUnrankDichoPre compares every unrank of the small tables, on math/big and on machine words, with UnrankDicho on math/big.
+func TestUnrankDichoPre(t *testing.T) {
+	fixed := parallelunranking.FixedWidth
+	parallelunranking.FixedWidth = false
+	defer func() { parallelunranking.FixedWidth = fixed }()
+
+	for n := 1; n <= 9; n++ {
+		for k := 1; k <= n; k++ {
+			table := NewStirlingTable(n, k, 2)
+			u, words := NewUnranker(n, k, Options{}), NewUnranker(n, k, DefaultOptions())
+			if words.Width() != 64 {
+				t.Fatalf("NewUnranker(%d, %d).Width() = %d, want 64", n, k, words.Width())
+			}
+			count := u.Count()
+			for r := big.NewInt(0); r.Cmp(count) < 0; r.Add(r, big.NewInt(1)) {
+				want := fmt.Sprint(parallelunranking.UnrankDicho(n, k, *r, 4))
+				for _, vs3 := range []int{0, 1, 2} {
+					if got := fmt.Sprint(UnrankDichoPre(table, *r, vs3)); got != want {
+						t.Fatalf("UnrankDichoPre(%d, %d, %v, %d) = %s, want %s", n, k, r, vs3, got, want)
+					}
+					if got := fmt.Sprint(u.Unrank(r, vs3)); got != want {
+						t.Fatalf("Unranker(%d, %d).Unrank(%v, %d) = %s, want %s", n, k, r, vs3, got, want)
+					}
+					if got := fmt.Sprint(words.Unrank(r, vs3)); got != want {
+						t.Fatalf("Unranker(%d, %d).Unrank(%v, %d) on machine words = %s, want %s", n, k, r, vs3, got, want)
+					}
+				}
+			}
+		}
+	}
+}
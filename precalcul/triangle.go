@@ -0,0 +1,203 @@
+package precalcul
+
+import (
+	"math/big"
+	"runtime"
+	"sync"
+
+	"github.com/AMAURYCU/setpartition_unrank/types"
+)
+
+// TriangleWorkers is the number of goroutines computing the StirlingTable of an Unranker.
+var TriangleWorkers = runtime.NumCPU()
+
+// the columns and the lines of the tiles of the band computed by one goroutine
+const bandTileColumns, bandTileLines = 16, 64
+
+//  Compute the band of the Stirling triangle read by UnrankDichoPre.
+/*
+Return band, with band[j][i-j] = S(i,j) for 0 <= j <= k and j <= i <= j+n-k, the only numbers of the
+triangle read when unranking the set partitions of [|1,n|] into k blocks. The band is cut into tiles,
+and the tiles of each anti-diagonal are computed by workers goroutines at the same time, a tile only
+needing the tiles on its left and above it. k must be in [|0,n|].
+
+Example usage:
+
+	band := precalcul.StirlingBand(5, 3, 4)
+	fmt.Println(&band[3][2]) // Output: 25
+*/
+func StirlingBand(n, k, workers int) [][]big.Int {
+	lines := n - k + 1
+	band := make([][]big.Int, k+1)
+	for j := range band {
+		band[j] = make([]big.Int, lines)
+	}
+	band[0][0].SetInt64(1)
+	if k == 0 {
+		return band
+	}
+	for m := range band[1] {
+		band[1][m].SetInt64(1)
+	}
+	// the tile (a, b) holds the columns 2 + a*bandTileColumns... and the lines b*bandTileLines...
+	tilesA := (k - 1 + bandTileColumns - 1) / bandTileColumns
+	tilesB := (lines + bandTileLines - 1) / bandTileLines
+	tile := func(a, b int) {
+		jb := new(big.Int)
+		for j := 2 + a*bandTileColumns; j < min(k+1, 2+(a+1)*bandTileColumns); j++ {
+			jb.SetInt64(int64(j))
+			for m := b * bandTileLines; m < min(lines, (b+1)*bandTileLines); m++ {
+				// S(j+m, j) = j S(j+m-1, j) + S(j+m-1, j-1)
+				if m > 0 {
+					band[j][m].Mul(jb, &band[j][m-1])
+				}
+				band[j][m].Add(&band[j][m], &band[j-1][m])
+			}
+		}
+	}
+	workers = max(1, workers)
+	for diagonal := 0; diagonal < tilesA+tilesB-1; diagonal++ {
+		a0, a1 := max(0, diagonal-tilesB+1), min(tilesA-1, diagonal)
+		if workers == 1 || a0 == a1 {
+			for a := a0; a <= a1; a++ {
+				tile(a, diagonal-a)
+			}
+			continue
+		}
+		var wg sync.WaitGroup
+		next := make(chan int, a1-a0+1)
+		for a := a0; a <= a1; a++ {
+			next <- a
+		}
+		close(next)
+		for w := 0; w < min(workers, a1-a0+1); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for a := range next {
+					tile(a, diagonal-a)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+	return band
+}
+
+// StirlingTable holds the band of the Stirling triangle read when unranking the set partitions of [|1,n|] into k blocks.
+type StirlingTable struct {
+	n, k int
+	// band[j][i-j] = S(i,j), as returned by StirlingBand
+	band [][]big.Int
+}
+
+// the numbers of a StirlingTable outside of its band, all 0, never modified
+var outsideBand big.Int
+
+//  Compute the Stirling numbers used by UnrankDichoPre.
+/*
+Return the StirlingTable of the set partitions of [|1,n|] into k blocks, for any n and k in [|1,n|],
+its band being computed by StirlingBand on workers goroutines. It only takes (k+1)(n-k+1) numbers,
+and can be shared by any number of unranks at the same time. For another k, all its numbers are 0.
+
+Example usage:
+
+	t := precalcul.NewStirlingTable(10, 5, precalcul.TriangleWorkers)
+	fmt.Println(t.At(10, 5), precalcul.UnrankDichoPre(t, *big.NewInt(42524), 2)) // Output: 42525 [[1 10] [2 9] [3 8] [4 7] [5 6]]
+*/
+func NewStirlingTable(n, k, workers int) *StirlingTable {
+	if k < 0 || k > n {
+		return &StirlingTable{n: n, k: k}
+	}
+	return &StirlingTable{n: n, k: k, band: StirlingBand(n, k, workers)}
+}
+
+// N returns the number of elements of the set partitions of t.
+func (t *StirlingTable) N() int {
+	return t.n
+}
+
+// K returns the number of blocks of the set partitions of t.
+func (t *StirlingTable) K() int {
+	return t.k
+}
+
+// At returns a copy of S(i,j) when j <= i <= j+n-k, and 0 outside of the band of t, as statistic.StirlingTriangle.
+func (t *StirlingTable) At(i, j int) *big.Int {
+	return new(big.Int).Set(t.at(i, j))
+}
+
+// at is At without the copy, the result must not be modified.
+func (t *StirlingTable) at(i, j int) *big.Int {
+	if j < 0 || j >= len(t.band) || i < j || i-j >= len(t.band[j]) {
+		return &outsideBand
+	}
+	return &t.band[j][i-j]
+}
+
+// Options of an Unranker.
+type Options struct {
+	// unrank on machine words, without reading the StirlingTable, when S(n,k) < 2^128
+	FixedWidth bool
+}
+
+// DefaultOptions returns the options of the fastest Unranker, on machine words when they are enough.
+func DefaultOptions() Options {
+	return Options{FixedWidth: true}
+}
+
+// Unranker unranks the set partitions of [|1,n|] into k blocks with its own StirlingTable and binomial coefficients.
+type Unranker struct {
+	opts      Options
+	table     *StirlingTable
+	binomials *types.BinomialCache
+	// the unranker on machine words, nil when the option FixedWidth is false or S(n,k) >= 2^128
+	fixed *types.FixedWidthUnranker
+}
+
+// NewUnranker returns the Unranker of the set partitions of [|1,n|] into k blocks with the options opts, its table being computed on TriangleWorkers goroutines.
+func NewUnranker(n, k int, opts Options) *Unranker {
+	u := &Unranker{opts: opts, table: NewStirlingTable(n, k, TriangleWorkers), binomials: types.NewBinomialCache(BinomialCacheWords)}
+	if opts.FixedWidth {
+		u.fixed = types.NewFixedWidthUnranker(n, k)
+	}
+	return u
+}
+
+// Options returns the options of u.
+func (u *Unranker) Options() Options {
+	return u.opts
+}
+
+// Width returns the number of bits of the machine words used by u, 64 or 128, or 0 for math/big.
+func (u *Unranker) Width() int {
+	if u.fixed == nil {
+		return 0
+	}
+	return u.fixed.Width()
+}
+
+// Table returns the StirlingTable of u.
+func (u *Unranker) Table() *StirlingTable {
+	return u.table
+}
+
+// Count returns S(n,k), the number of set partitions of u.
+func (u *Unranker) Count() *big.Int {
+	return u.table.At(u.table.n, u.table.k)
+}
+
+//  Unrank set partition lexicographicaly with the table of the Unranker.
+/*
+Same as UnrankDichoPre, the binomial coefficients being kept from one unrank to the next, and the
+machine words of the option FixedWidth replacing the table when they are enough. Several goroutines
+may call it at the same time.
+
+Example usage:
+
+	u := precalcul.NewUnranker(5, 3, precalcul.DefaultOptions())
+	fmt.Println(u.Unrank(big.NewInt(10), 2)) // Output: [[1 2 3] [4] [5]]
+*/
+func (u *Unranker) Unrank(rank *big.Int, vs3 int) [][]int {
+	return unrankDichoPre(u.table, u.binomials, u.fixed, u.table.n, u.table.k, *rank, vs3)
+}
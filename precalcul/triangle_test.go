@@ -0,0 +1,79 @@
+package precalcul
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/AMAURYCU/setpartition_unrank/types"
+)
+
+func TestStirlingTable(t *testing.T) {
+	for n := 0; n <= 80; n += 5 {
+		triangle := types.StirlingColumns(n+1, n+1)
+		for k := -1; k <= n+1; k++ {
+			for _, workers := range []int{1, 4} {
+				table := NewStirlingTable(n, k, workers)
+				for i := -1; i <= n+1; i++ {
+					for j := -1; j <= k+1; j++ {
+						want := "0"
+						// only the band of the lines j to j+n-k of the columns 0 to k is kept
+						if 0 <= k && k <= n && 0 <= j && j <= k && j <= i && i <= j+n-k {
+							want = triangle[j][i].String()
+						}
+						got := table.At(i, j)
+						if got.String() != want {
+							t.Fatalf("NewStirlingTable(%d, %d, %d).At(%d, %d) = %v, want %s", n, k, workers, i, j, got, want)
+						}
+						// the copy is the caller's
+						got.SetInt64(-1)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestUnrankerCount(t *testing.T) {
+	for n := 1; n <= 30; n++ {
+		for k := 1; k <= n; k++ {
+			u := NewUnranker(n, k, DefaultOptions())
+			c := u.Count()
+			if want := types.StirlingColumns(n, k)[k][n]; c.Cmp(&want) != 0 {
+				t.Fatalf("NewUnranker(%d, %d).Count() = %v, want %v", n, k, c, &want)
+			}
+			c.SetInt64(0)
+			if u.Count().Sign() == 0 {
+				t.Fatalf("NewUnranker(%d, %d).Count() was changed by its caller", n, k)
+			}
+		}
+	}
+}
+
+// TestUnrankerFixedWidth compares the unranks of the Unranker on machine words with the ones reading its table.
+func TestUnrankerFixedWidth(t *testing.T) {
+	rg := rand.New(rand.NewSource(1))
+	for _, c := range []struct{ n, k, width int }{{6, 3, 64}, {25, 12, 64}, {40, 5, 128}, {30, 15, 128}, {45, 20, 0}} {
+		fixed, table := NewUnranker(c.n, c.k, DefaultOptions()), NewUnranker(c.n, c.k, Options{})
+		if w := fixed.Width(); w != c.width {
+			t.Fatalf("NewUnranker(%d, %d).Width() = %d, want %d", c.n, c.k, w, c.width)
+		}
+		if w := table.Width(); w != 0 {
+			t.Fatalf("NewUnranker(%d, %d) without FixedWidth has Width() = %d", c.n, c.k, w)
+		}
+		count := fixed.Count()
+		ranks := []*big.Int{big.NewInt(0), new(big.Int).Sub(count, big.NewInt(1))}
+		for i := 0; i < 30; i++ {
+			ranks = append(ranks, new(big.Int).Rand(rg, count))
+		}
+		for _, r := range ranks {
+			for _, vs3 := range []int{0, 2} {
+				got, want := fixed.Unrank(r, vs3), table.Unrank(r, vs3)
+				if fmt.Sprint(got) != fmt.Sprint(want) {
+					t.Fatalf("Unrank(%v, %d) of (%d, %d) = %v on %d bits, %v with the table", r, vs3, c.n, c.k, got, c.width, want)
+				}
+			}
+		}
+	}
+}
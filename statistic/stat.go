@@ -6,22 +6,24 @@ import (
 	"math/rand"
 	"os"
 	"os/exec"
+	"runtime"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/AMAURYCU/setpartition_unrank/parallelunranking"
 	"github.com/AMAURYCU/setpartition_unrank/precalcul"
+	"github.com/AMAURYCU/setpartition_unrank/types"
 )
 
-func StirlingTriangle(n, k int) [2000][2000]*big.Int {
+func StirlingTriangle(n, k int) [][]*big.Int {
 	if k < 0 || k > n {
-		return [2000][2000]*big.Int{}
+		return nil
 	}
 
-	triangle := [2000][2000]*big.Int{}
+	triangle := make([][]*big.Int, n+1)
 	for i := 0; i <= n; i++ {
-		triangle[i] = [2000]*big.Int{}
+		triangle[i] = make([]*big.Int, k+1)
 		for j := 0; j <= k; j++ {
 			triangle[i][j] = new(big.Int)
 		}
@@ -83,22 +85,22 @@ func Stat(bsup int, nbPoints int, repetitions int, verbose bool) {
 			StirlingColumn1 := couple.Col1
 
 			r.Rand(rg, &StirlingColumn1[bsup])
+			var stats *parallelunranking.Stats
 			for o := 0; o < 5; o++ {
 				startTime := time.Now().UnixMicro()
-				parallelunranking.UnrankDicho(bsup, k, r, o)
+				_, stats = parallelunranking.UnrankStats(bsup, k, r, o)
 				endTime := time.Now().UnixMicro()
 				sumTime[o] += endTime - startTime
 				values[o] = append(values[o], endTime-startTime)
-				acol1 += parallelunranking.WaitingTime
-				parallelunranking.WaitingTime = 0
+				acol1 += stats.ColumnsTime / 1000
 
 			}
-			precalcul.StirlingMatrix = StirlingTriangle(bsup, k)
+			table := precalcul.NewStirlingTable(bsup, k, precalcul.TriangleWorkers)
 			startTime := time.Now().UnixMicro()
-			precalcul.UnrankDichoPre(bsup, k, r, 0)
+			precalcul.UnrankDichoPre(table, r, 0)
 			endTime := time.Now().UnixMicro()
 			sumtimepre += endTime - startTime
-			at += parallelunranking.TimeTotal
+			at += stats.TotalWait()
 
 		}
 		waiting = append(waiting, float64(at)/float64(repetitions))
@@ -184,8 +186,6 @@ func PrintMatrix(mat [][]int64) string {
 }
 
 func Graph3d(nmax int, dn, dk int, repetition int) ([][]int64, []int64, []int64) {
-	sg := rand.NewSource(time.Now().UnixNano())
-	rg := rand.New(sg)
 	mat := make([][]int64, 0)
 	valn := make([]int64, 0)
 	valk := make([]int64, 0)
@@ -194,13 +194,7 @@ func Graph3d(nmax int, dn, dk int, repetition int) ([][]int64, []int64, []int64)
 		res := make([]int64, 0)
 		for k := 2; k < n; k += dk {
 			sumtime := int64(0)
-			for useless := 0; useless < repetition; useless++ {
-
-				couple := *parallelunranking.Stirling2Columns(n, k)
-				parallelunranking.StirlingColumn0 = couple.Col0
-				parallelunranking.StirlingColumn1 = couple.Col1
-				var r big.Int
-				r.Rand(rg, &parallelunranking.StirlingColumn0[n])
+			for _, r := range randomRanks(n, k, repetition) {
 				startTime := time.Now().UnixMilli()
 				parallelunranking.UnrankDicho(n, k, r, 4)
 				endTime := time.Now().UnixMilli()
@@ -219,3 +213,345 @@ func Graph3d(nmax int, dn, dk int, repetition int) ([][]int64, []int64, []int64)
 	return mat, valn, valk
 
 }
+
+/*
+Compare the time (in μs) to list every set partition of [|1,n|] into k blocks with a call to
+UnrankDicho per rank and with parallelunranking.Enumerate, both producing the same sequence.
+*/
+func EnumerationBenchmark(n, k int, verbose bool) (int64, int64) {
+	c := parallelunranking.Stirling2Columns(n, k).Col1[n]
+	startTime := time.Now().UnixMicro()
+	for r := big.NewInt(0); r.Cmp(&c) < 0; r.Add(r, big.NewInt(1)) {
+		parallelunranking.UnrankDicho(n, k, *r, 4)
+	}
+	unrankTime := time.Now().UnixMicro() - startTime
+
+	startTime = time.Now().UnixMicro()
+	count := 0
+	parallelunranking.Enumerate(n, k, func(rgs []int) bool {
+		count++
+		return true
+	})
+	enumerateTime := time.Now().UnixMicro() - startTime
+	if verbose {
+		fmt.Println("n", n, "k", k, "partitions", count)
+		fmt.Println("UnrankDicho loop", unrankTime, "μs, Enumerate", enumerateTime, "μs")
+	}
+	return unrankTime, enumerateTime
+}
+
+// naivePermutationUnrank is the former quadratic version of types.LexicographicPermutationUnrank, deleting from a slice.
+func naivePermutationUnrank(n int, Pos [][]int) [][]int {
+	L := make([]int, n)
+	for i := 0; i < n; i++ {
+		L[i] = i + 1
+	}
+	var P [][]int
+	for b := 0; b < len(Pos); b++ {
+		p := []int{}
+		for _, i := range Pos[b] {
+			p = append(p, L[i])
+			L = append(L[:i], L[i+1:]...)
+		}
+		P = append(P, p)
+	}
+	return P
+}
+
+/*
+Compare the time (in μs) of the quadratic and of the Fenwick tree permutation unranking on
+the position codes of a random set partition of [|1,n|] into k blocks, repeated repetitions times.
+*/
+func PermutationUnrankBenchmark(n, k, repetitions int, verbose bool) (int64, int64) {
+	sg := rand.NewSource(time.Now().UnixNano())
+	rg := rand.New(sg)
+	// random position codes : k-1 blocks of random sizes then the block of the elements left
+	Pos := make([][]int, 0, k)
+	left := n
+	for b := 0; b < k-1; b++ {
+		size := 1 + rg.Intn((left-(k-1-b))/(k-b))
+		block := make([]int, size)
+		for j := range block {
+			block[j] = rg.Intn(left - j)
+		}
+		Pos = append(Pos, block)
+		left -= size
+	}
+	Pos = append(Pos, make([]int, left))
+
+	startTime := time.Now().UnixMicro()
+	for i := 0; i < repetitions; i++ {
+		naivePermutationUnrank(n, Pos)
+	}
+	naiveTime := time.Now().UnixMicro() - startTime
+	startTime = time.Now().UnixMicro()
+	for i := 0; i < repetitions; i++ {
+		types.LexicographicPermutationUnrank(n, Pos)
+	}
+	fenwickTime := time.Now().UnixMicro() - startTime
+	if verbose {
+		fmt.Println("n", n, "k", k, "quadratic", naiveTime, "μs, fenwick", fenwickTime, "μs")
+	}
+	return naiveTime, fenwickTime
+}
+
+// randomRanks returns repetitions ranks drawn uniformly among the set partitions of [|1,n|] into k blocks.
+func randomRanks(n, k, repetitions int) []big.Int {
+	rg := rand.New(rand.NewSource(time.Now().UnixNano()))
+	c := parallelunranking.Stirling2Columns(n, k).Col1[n]
+	ranks := make([]big.Int, repetitions)
+	for i := range ranks {
+		ranks[i].Rand(rg, &c)
+	}
+	return ranks
+}
+
+/*
+Compare the time (in μs) of repetitions calls to UnrankDicho on random ranks with and without
+the binomial cache of the S3 formulas. The two results of each rank are checked to be equal.
+*/
+func BinomialCacheBenchmark(n, k, repetitions int, verbose bool) (int64, int64) {
+	ranks := randomRanks(n, k, repetitions)
+	limit := parallelunranking.BinomialCacheWords
+	defer func() { parallelunranking.BinomialCacheWords = limit }()
+
+	results := [2][][][]int{make([][][]int, repetitions), make([][][]int, repetitions)}
+	times := [2]int64{}
+	for i, words := range []int{0, limit} {
+		parallelunranking.BinomialCacheWords = words
+		startTime := time.Now().UnixMicro()
+		for j, r := range ranks {
+			results[i][j] = parallelunranking.UnrankDicho(n, k, r, 4)
+		}
+		times[i] = time.Now().UnixMicro() - startTime
+	}
+	for j := range ranks {
+		if fmt.Sprint(results[0][j]) != fmt.Sprint(results[1][j]) {
+			panic("BinomialCacheBenchmark: the binomial cache changed the result")
+		}
+	}
+	if verbose {
+		fmt.Println("n", n, "k", k, "without cache", times[0], "μs, with cache", times[1], "μs")
+	}
+	return times[0], times[1]
+}
+
+/*
+Compare the time (in μs) of repetitions calls to Unranker.Unrank on random ranks with the sums of the
+S3 formula computed on one goroutine, then shared between workers goroutines whatever their number of
+terms. The two results of each call are checked to be equal. Running it for growing n shows from which
+size the parallel sums pay off on the machine, see parallelunranking.DefaultS3ParallelThreshold.
+*/
+func ParallelS3Benchmark(n, k, workers, repetitions int, verbose bool) (int64, int64) {
+	ranks := randomRanks(n, k, repetitions)
+	opts := parallelunranking.DefaultOptions()
+	opts.S3Workers = 1
+	sequential := parallelunranking.NewUnranker(opts)
+	opts.S3Workers, opts.S3ParallelThreshold = workers, 1
+	parallel := parallelunranking.NewUnranker(opts)
+
+	results := make([][][]int, repetitions)
+	startTime := time.Now().UnixMicro()
+	for i := range ranks {
+		results[i] = sequential.Unrank(n, k, &ranks[i])
+	}
+	sequentialTime := time.Now().UnixMicro() - startTime
+	startTime = time.Now().UnixMicro()
+	for i := range ranks {
+		if fmt.Sprint(parallel.Unrank(n, k, &ranks[i])) != fmt.Sprint(results[i]) {
+			panic("ParallelS3Benchmark: the parallel sums changed the result")
+		}
+	}
+	parallelTime := time.Now().UnixMicro() - startTime
+	if verbose {
+		fmt.Println("n", n, "k", k, "sequential", sequentialTime, "μs,", workers, "workers", parallelTime, "μs")
+	}
+	return sequentialTime, parallelTime
+}
+
+/*
+Compare the time (in μs) of repetitions calls to Unranker.Unrank on random ranks with the sequential
+binary search on the elements of the blocks, then with fanOut-1 midpoints evaluated concurrently at
+each step. The two results of each call are checked to be equal. Call it for several n and k to see
+where the speculative search pays off.
+*/
+func ParallelSearchBenchmark(n, k, fanOut, repetitions int, verbose bool) (int64, int64) {
+	ranks := randomRanks(n, k, repetitions)
+	opts := parallelunranking.DefaultOptions()
+	sequential := parallelunranking.NewUnranker(opts)
+	opts.SearchFanOut = fanOut
+	parallel := parallelunranking.NewUnranker(opts)
+
+	results := make([][][]int, repetitions)
+	startTime := time.Now().UnixMicro()
+	for i := range ranks {
+		results[i] = sequential.Unrank(n, k, &ranks[i])
+	}
+	sequentialTime := time.Now().UnixMicro() - startTime
+	startTime = time.Now().UnixMicro()
+	for i := range ranks {
+		if fmt.Sprint(parallel.Unrank(n, k, &ranks[i])) != fmt.Sprint(results[i]) {
+			panic("ParallelSearchBenchmark: the parallel search changed the result")
+		}
+	}
+	parallelTime := time.Now().UnixMicro() - startTime
+	if verbose {
+		fmt.Println("n", n, "k", k, "binary search", sequentialTime, "μs, fan-out", fanOut, parallelTime, "μs")
+	}
+	return sequentialTime, parallelTime
+}
+
+/*
+Compare the total time (in μs) waited for the previous Stirling columns during repetitions calls
+to Unranker.UnrankStats on random ranks, with one column computed ahead, then depth columns.
+*/
+func PipelineBenchmark(n, k, depth, repetitions int, verbose bool) (int64, int64) {
+	ranks := randomRanks(n, k, repetitions)
+	waits := [2]int64{}
+	for i, d := range []int{1, depth} {
+		opts := parallelunranking.DefaultOptions()
+		opts.PipelineDepth = d
+		u := parallelunranking.NewUnranker(opts)
+		for j := range ranks {
+			_, stats := u.UnrankStats(n, k, &ranks[j])
+			waits[i] += stats.TotalWait()
+		}
+	}
+	if verbose {
+		fmt.Println("n", n, "k", k, "waited with 1 column ahead", waits[0], "μs, with", depth, "columns ahead", waits[1], "μs")
+	}
+	return waits[0], waits[1]
+}
+
+/*
+Compare the time (in μs) of repetitions calls to UnrankDicho on random ranks with math/big, then on
+machine words, S(n,k) having to be < 2^128. The two results of each call are checked to be equal.
+*/
+func FixedWidthBenchmark(n, k, repetitions int, verbose bool) (int64, int64) {
+	ranks := randomRanks(n, k, repetitions)
+	fixed := parallelunranking.FixedWidth
+	defer func() { parallelunranking.FixedWidth = fixed }()
+
+	results := make([][][]int, repetitions)
+	parallelunranking.FixedWidth = false
+	startTime := time.Now().UnixMicro()
+	for i := range ranks {
+		results[i] = parallelunranking.UnrankDicho(n, k, ranks[i], 4)
+	}
+	bigTime := time.Now().UnixMicro() - startTime
+	parallelunranking.FixedWidth = true
+	fixedResults := make([][][]int, repetitions)
+	startTime = time.Now().UnixMicro()
+	for i := range ranks {
+		fixedResults[i] = parallelunranking.UnrankDicho(n, k, ranks[i], 4)
+	}
+	fixedTime := time.Now().UnixMicro() - startTime
+	for i := range ranks {
+		if fmt.Sprint(fixedResults[i]) != fmt.Sprint(results[i]) {
+			panic("FixedWidthBenchmark: the machine words changed the result")
+		}
+	}
+	if verbose {
+		opts := parallelunranking.DefaultOptions()
+		fmt.Println("n", n, "k", k, "math/big", bigTime, "μs,", parallelunranking.NewUnranker(opts).Width(n, k), "bits", fixedTime, "μs")
+	}
+	return bigTime, fixedTime
+}
+
+/*
+Compare the time (in μs) of the Stirling columns S(., k-1) and S(., k) until the line n computed
+with the recurrence, then with the explicit formula on workers goroutines. Call it for several n, k
+and workers to see where the cost model of parallelunranking.AutoColumns should switch.
+*/
+func ColumnsBenchmark(n, k, workers int, verbose bool) (int64, int64) {
+	startTime := time.Now().UnixMicro()
+	parallelunranking.Stirling2ColumnsRecurrence(n, k)
+	recurrenceTime := time.Now().UnixMicro() - startTime
+	startTime = time.Now().UnixMicro()
+	parallelunranking.Stirling2ColumnsExplicit(n, k, workers)
+	explicitTime := time.Now().UnixMicro() - startTime
+	if verbose {
+		fmt.Println("n", n, "k", k, "recurrence", recurrenceTime, "μs, explicit on", workers, "workers", explicitTime, "μs")
+	}
+	return recurrenceTime, explicitTime
+}
+
+/*
+Check that precalcul.NewStirlingTable, with workers goroutines, gives the same numbers as
+StirlingTriangle for all 1 <= k <= n <= nmax, on the lines i <= n of the columns j <= k.
+*/
+func StirlingTriangleCheck(nmax, workers int, verbose bool) bool {
+	for n := 1; n <= nmax; n++ {
+		for k := 1; k <= n; k++ {
+			triangle := StirlingTriangle(n, k)
+			table := precalcul.NewStirlingTable(n, k, workers)
+			for i := 0; i <= n; i++ {
+				for j := 0; j <= k; j++ {
+					if triangle[i][j].Cmp(table.At(i, j)) != 0 {
+						if verbose {
+							fmt.Println("n", n, "k", k, "S(", i, ",", j, ") differs")
+						}
+						return false
+					}
+				}
+			}
+		}
+	}
+	return true
+}
+
+/*
+Compare the time (in μs) of StirlingTriangle(n, k), then of precalcul.NewStirlingTable(n, k, workers)
+computing only the band read by precalcul.UnrankDichoPre on workers goroutines.
+*/
+func StirlingTriangleBenchmark(n, k, workers int, verbose bool) (int64, int64) {
+	startTime := time.Now().UnixMicro()
+	StirlingTriangle(n, k)
+	sequentialTime := time.Now().UnixMicro() - startTime
+	startTime = time.Now().UnixMicro()
+	precalcul.NewStirlingTable(n, k, workers)
+	parallelTime := time.Now().UnixMicro() - startTime
+	if verbose {
+		fmt.Println("n", n, "k", k, "StirlingTriangle", sequentialTime, "μs, band on", workers, "workers", parallelTime, "μs")
+	}
+	return sequentialTime, parallelTime
+}
+
+// allocations returns the number of heap allocations done by f.
+func allocations(f func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	f()
+	runtime.ReadMemStats(&after)
+	return after.Mallocs - before.Mallocs
+}
+
+/*
+Compare the heap allocations per call of repetitions calls to UnrankDicho on random ranks, then of
+Unranker.UnrankInto reusing its big.Int buffers and the previous result, both on math/big.
+*/
+func AllocationBenchmark(n, k, repetitions int, verbose bool) (uint64, uint64) {
+	ranks := randomRanks(n, k, repetitions)
+	opts := parallelunranking.DefaultOptions()
+	opts.FixedWidth = false
+	u := parallelunranking.NewUnranker(opts)
+	fixed := parallelunranking.FixedWidth
+	parallelunranking.FixedWidth = false
+	defer func() { parallelunranking.FixedWidth = fixed }()
+
+	dichoAllocs := allocations(func() {
+		for i := range ranks {
+			parallelunranking.UnrankDicho(n, k, ranks[i], 4)
+		}
+	}) / uint64(repetitions)
+	var p [][]int
+	intoAllocs := allocations(func() {
+		for i := range ranks {
+			p = u.UnrankInto(p, n, k, &ranks[i])
+		}
+	}) / uint64(repetitions)
+	if verbose {
+		fmt.Println("n", n, "k", k, "UnrankDicho", dichoAllocs, "allocs/op, Unranker.UnrankInto", intoAllocs, "allocs/op")
+	}
+	return dichoAllocs, intoAllocs
+}
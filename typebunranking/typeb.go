@@ -0,0 +1,279 @@
+// Package typebunranking provides functions to unrank type-B set partitions lexicographicaly
+//
+// A type-B set partition of {±1,...,±n} is a set partition stable under x -> -x with at most
+// one block Z, the zero block, such that Z = -Z. The others blocks come in pairs {B, -B}.
+// The partitions with exactly k pairs are counted by the type-B Stirling numbers
+// S_B(n,k) = S_B(n-1,k-1) + (2k+1)S_B(n-1,k).
+//
+// A partition is written as a [][]int of length k+1 : the k first lists are the representatives
+// of the pairs, the representative of {B, -B} being the one whose element of smallest absolute
+// value is positive, its elements are sorted by absolute value. The representatives are sorted by
+// their first element and the last list is the zero block, sorted, possibly empty.
+// Elements are compared by absolute value then sign, -x coming right before x, and partitions
+// are compared representative after representative, a prefix being smaller.
+package typebunranking
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/AMAURYCU/setpartition_unrank/types"
+)
+
+// Return the k-1 and the k type-B stirling triangle columns until the line n.
+func StirlingB2Columns(n, k int) *types.CoupleColumns {
+	prev := make([]big.Int, n+1)
+	curr := make([]big.Int, n+1)
+	for i := range prev {
+		prev[i].SetInt64(1)
+	}
+	if k == 0 {
+		return &types.CoupleColumns{Col0: make([]big.Int, n+1), Col1: prev}
+	}
+	for j := 1; j <= k; j++ {
+		curr[0].SetInt64(0)
+		for i := 1; i <= n; i++ {
+			curr[i].Mul(big.NewInt(int64(2*j+1)), &curr[i-1])
+			curr[i].Add(&curr[i], &prev[i-1])
+		}
+		if j < k {
+			prev, curr = curr, prev
+		}
+	}
+	return &types.CoupleColumns{Col0: prev, Col1: curr}
+}
+
+// Return the type-B stirling number S_B(n, k).
+func Count(n, k int) *big.Int {
+	if k < 0 || k > n {
+		return big.NewInt(0)
+	}
+	c := StirlingB2Columns(n, k).Col1[n]
+	return &c
+}
+
+// computePreviousColumn computes the k-1 column from the k one, S_B(t-1, k-1) = S_B(t, k) - (2k+1)S_B(t-1, k).
+func computePreviousColumn(column []big.Int, n, k int, resultChan chan []big.Int) {
+	resultChan <- types.PreviousColumn(nil, column, n, int64(2*k+1))
+}
+
+//  Unrank type-B set partition lexicographicaly.
+/*
+This function takes 3 arguments as parameters :
+- n : int, the elements are ±1,...,±n.
+- k : int, the number of desired pairs of non-zero blocks.
+- rank : big.Int, the rank of the desired type-B set partition, 0 <= rank < S_B(n,k).
+
+The result holds the k representatives followed by the zero block (see the package documentation),
+it is nil when k is not in [|0,n|] or rank is not in [|0,S_B(n,k)-1|].
+Example usage:
+
+	result := typebunranking.Unrank(3, 1, *big.NewInt(10))
+	fmt.Println(result) // Output: [[2 -3] [-1 1]]
+*/
+func Unrank(n, k int, rank big.Int) [][]int {
+	if k < 0 || k > n || rank.Sign() < 0 || rank.Cmp(Count(n, k)) >= 0 {
+		return nil
+	}
+	res := make([][]int, 0)
+	zero := make([]int, 0)
+	remaining := make([]int, n)
+	for i := range remaining {
+		remaining[i] = i + 1
+	}
+	r := new(big.Int).Set(&rank)
+	couple := *StirlingB2Columns(n, k)
+	column0 := couple.Col0
+	column1 := couple.Col1
+	chanRes := make(chan []big.Int)
+	binomials := types.NewBinomialCache(types.DefaultBinomialCacheWords)
+
+	for k > 0 {
+		if k > 1 {
+			go computePreviousColumn(column0, len(remaining), k-1, chanRes)
+		}
+		N := len(remaining)
+
+		// the elements before the smallest element of the block go to the zero block
+		limit := new(big.Int).Sub(&column1[N], r)
+		m := types.Search(1, N, limit, func(i int) *big.Int { return &column1[N-i] })
+		r.Sub(r, new(big.Int).Sub(&column1[N], &column1[N-m+1]))
+		zero = append(zero, remaining[:m-1]...)
+		remaining = remaining[m-1:]
+		N = len(remaining)
+
+		block := []int{remaining[0]}
+		taken := []int{0}
+		d, R := 1, N-1
+		for {
+			// the block may stop here, this comes first
+			if r.Cmp(&column0[R]) < 0 {
+				break
+			}
+			r.Sub(r, &column0[R])
+			total := types.Tail(column0, N-d, R, k-1, 1, binomials)
+			limit := new(big.Int).Sub(total, r)
+			e := types.Search(d+1, N, limit, func(i int) *big.Int { return types.Tail(column0, N-i, R, k-1, 1, binomials) })
+			r.Sub(r, total.Sub(total, types.Tail(column0, N-e+1, R, k-1, 1, binomials)))
+			sub := types.Tail(column0, N-e, R-1, k-1, 1, binomials)
+			if r.Cmp(sub) < 0 {
+				block = append(block, -remaining[e-1])
+			} else {
+				r.Sub(r, sub)
+				block = append(block, remaining[e-1])
+			}
+			taken = append(taken, e-1)
+			d, R = e, R-1
+		}
+		res = append(res, block)
+		for i := len(taken) - 1; i >= 0; i-- {
+			remaining = append(remaining[:taken[i]], remaining[taken[i]+1:]...)
+		}
+
+		k--
+		if k > 0 {
+			column1 = column0
+			column0 = <-chanRes
+		}
+	}
+	zero = append(zero, remaining...)
+	res = append(res, signedZeroBlock(zero))
+	return res
+}
+
+func signedZeroBlock(zero []int) []int {
+	res := make([]int, 0, 2*len(zero))
+	for i := len(zero) - 1; i >= 0; i-- {
+		res = append(res, -zero[i])
+	}
+	return append(res, zero...)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+//  Rank type-B set partition lexicographicaly.
+/*
+This function is the inverse of Unrank. The pairs may be given in any order, by any of
+their two blocks, and the zero block may be omitted. The result is nil when partition is not
+a type-B set partition of {±1,...,±n} with k pairs.
+
+Example usage:
+
+	rank := typebunranking.Rank(3, 1, [][]int{{-2, 3}, {1, -1}})
+	fmt.Println(rank) // Output: 10
+*/
+func Rank(n, k int, partition [][]int) *big.Int {
+	blocks := make([][]int, 0, k)
+	seen := make([]bool, n+1)
+	zeros := 0
+	for _, block := range partition {
+		b := append([]int{}, block...)
+		sort.Slice(b, func(i, j int) bool {
+			if abs(b[i]) != abs(b[j]) {
+				return abs(b[i]) < abs(b[j])
+			}
+			return b[i] < b[j]
+		})
+		zero := len(b) == 0 || (len(b) > 1 && b[0] == -b[1])
+		for i, x := range b {
+			if x == 0 || abs(x) > n {
+				return nil
+			}
+			if zero {
+				// the zero block holds x and -x together
+				if i%2 == 0 && (i+1 == len(b) || b[i+1] != -x || x > 0) {
+					return nil
+				}
+				if i%2 == 1 {
+					continue
+				}
+			}
+			if seen[abs(x)] {
+				return nil
+			}
+			seen[abs(x)] = true
+		}
+		if zero {
+			zeros++
+			continue
+		}
+		if b[0] < 0 {
+			for i := range b {
+				b[i] = -b[i]
+			}
+		}
+		blocks = append(blocks, b)
+	}
+	if len(blocks) != k || zeros > 1 {
+		return nil
+	}
+	if zeros == 1 {
+		for x := 1; x <= n; x++ {
+			if !seen[x] {
+				return nil
+			}
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i][0] < blocks[j][0] })
+
+	position := make([]int, n+1)
+	remaining := make([]int, n)
+	for i := range remaining {
+		remaining[i] = i + 1
+	}
+	res := big.NewInt(0)
+	couple := *StirlingB2Columns(n, k)
+	column0 := couple.Col0
+	column1 := couple.Col1
+	chanRes := make(chan []big.Int)
+	binomials := types.NewBinomialCache(types.DefaultBinomialCacheWords)
+
+	for _, block := range blocks {
+		if k > 1 {
+			go computePreviousColumn(column0, len(remaining), k-1, chanRes)
+		}
+		for i, x := range remaining {
+			position[x] = i + 1
+		}
+		N := len(remaining)
+		m := position[block[0]]
+		res.Add(res, new(big.Int).Sub(&column1[N], &column1[N-m+1]))
+		N -= m - 1
+
+		d, R := 1, N-1
+		for _, x := range block[1:] {
+			e := position[abs(x)] - m + 1
+			res.Add(res, &column0[R])
+			res.Add(res, types.Tail(column0, N-d, R, k-1, 1, binomials))
+			res.Sub(res, types.Tail(column0, N-e+1, R, k-1, 1, binomials))
+			if x > 0 {
+				res.Add(res, types.Tail(column0, N-e, R-1, k-1, 1, binomials))
+			}
+			d, R = e, R-1
+		}
+
+		next := make([]int, 0, len(remaining))
+		inBlock := make(map[int]bool)
+		for _, x := range block {
+			inBlock[abs(x)] = true
+		}
+		for _, x := range remaining[m-1:] {
+			if !inBlock[x] {
+				next = append(next, x)
+			}
+		}
+		remaining = next
+
+		k--
+		if k > 0 {
+			column1 = column0
+			column0 = <-chanRes
+		}
+	}
+	return res
+}
@@ -0,0 +1,113 @@
+package typebunranking
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/AMAURYCU/setpartition_unrank/internal/bruteforce"
+	"github.com/AMAURYCU/setpartition_unrank/parallelunranking"
+)
+
+// less compares two elements by absolute value then sign, -x coming right before x.
+func less(x, y int) bool {
+	return 2*abs(x)+min(1, max(0, x)) < 2*abs(y)+min(1, max(0, y))
+}
+
+// bruteForce returns the type-B set partitions of {±1,...,±n} with k pairs in the order of Unrank:
+// a zero block, the other elements split into k blocks, and a sign for each element but the first one of a block.
+func bruteForce(n, k int) [][][]int {
+	res := make([][][]int, 0)
+	for mask := 0; mask < 1<<n; mask++ {
+		zero, others := []int{}, []int{}
+		for x := 1; x <= n; x++ {
+			if mask&(1<<(x-1)) != 0 {
+				zero = append(zero, x)
+			} else {
+				others = append(others, x)
+			}
+		}
+		if k == 0 {
+			if len(others) == 0 {
+				res = append(res, [][]int{signedZeroBlock(zero)})
+			}
+			continue
+		}
+		parallelunranking.Enumerate(len(others), k, func(rgs []int) bool {
+			for signs := 0; signs < 1<<len(others); signs++ {
+				blocks := make([][]int, k)
+				ok := true
+				for i, b := range rgs {
+					x := others[i]
+					if signs&(1<<i) != 0 {
+						if len(blocks[b-1]) == 0 {
+							ok = false
+						}
+						x = -x
+					}
+					blocks[b-1] = append(blocks[b-1], x)
+				}
+				if ok {
+					res = append(res, append(blocks, signedZeroBlock(zero)))
+				}
+			}
+			return true
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		for b := 0; b < k; b++ {
+			p, q := res[i][b], res[j][b]
+			for e := 0; e < len(p) && e < len(q); e++ {
+				if p[e] != q[e] {
+					return less(p[e], q[e])
+				}
+			}
+			if len(p) != len(q) {
+				return len(p) < len(q)
+			}
+		}
+		return false
+	})
+	return res
+}
+
+func TestUnrankRank(t *testing.T) {
+	for n := 1; n <= 5; n++ {
+		for k := 1; k <= n; k++ {
+			want := bruteForce(n, k)
+			bruteforce.Check(t, fmt.Sprint(n, k), Count(n, k),
+				func(r big.Int) [][]int { return Unrank(n, k, r) },
+				func(p [][]int) *big.Int { return Rank(n, k, p) }, want)
+		}
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	for _, c := range []struct {
+		n, k int
+		rank *big.Int
+	}{{3, 4, big.NewInt(0)}, {3, -1, big.NewInt(0)}, {3, 1, Count(3, 1)}, {3, 1, big.NewInt(-1)}} {
+		if p := Unrank(c.n, c.k, *c.rank); p != nil {
+			t.Errorf("Unrank(%d, %d, %v) = %v, want nil", c.n, c.k, c.rank, p)
+		}
+	}
+	for _, c := range []struct {
+		k int
+		p [][]int
+	}{
+		{1, [][]int{{1}, {2}, {3}}},
+		{2, [][]int{{1, 2}, {2, 3}}},
+		{1, [][]int{{1, 2}, {-2}}},
+		{1, [][]int{{1, 4}, {-2, 2}}},
+		{1, [][]int{{1, 0}, {-2, 2}}},
+		{1, [][]int{{1}, {-2, 2, 3}}},
+		{1, [][]int{{1}, {-2, 2}}},
+		{1, [][]int{{1, 2, 3}, {}, {}}},
+		{0, [][]int{{-1, 1}, {-2, 2, -3, 3}}},
+	} {
+		if r := Rank(3, c.k, c.p); r != nil {
+			t.Errorf("Rank(3, %d, %v) = %v, want nil", c.k, c.p, r)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package types
+
+import (
+	"math/big"
+	"sync"
+)
+
+/*
+BinomialCache keeps the rows of binomial coefficients C(m, 0), ..., C(m, u) computed by the S3
+formulas. The rows are kept while the cache holds less than limit machine words, the others are
+computed and thrown away. A nil *BinomialCache computes every row. It can be shared between goroutines.
+*/
+type BinomialCache struct {
+	mutex sync.Mutex
+	rows  map[int][]big.Int
+	words int
+	limit int
+}
+
+// NewBinomialCache returns an empty cache holding at most limit machine words.
+func NewBinomialCache(limit int) *BinomialCache {
+	return &BinomialCache{rows: make(map[int][]big.Int), limit: limit}
+}
+
+// Row returns C(m, 0), ..., C(m, u) (at least), the result must not be modified.
+func (c *BinomialCache) Row(m, u int) []big.Int {
+	if u > m {
+		u = m
+	}
+	var row []big.Int
+	if c != nil {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		row = c.rows[m]
+	}
+	if len(row) > u {
+		return row
+	}
+	if len(row) == 0 {
+		row = append(row, *big.NewInt(1))
+	}
+	words := 0
+	for i := len(row); i <= u; i++ {
+		var b big.Int
+		b.Mul(&row[i-1], big.NewInt(int64(m-i+1)))
+		b.Div(&b, big.NewInt(int64(i)))
+		row = append(row, b)
+		words += len(b.Bits())
+	}
+	if c != nil && c.words+words <= c.limit {
+		c.rows[m] = row
+		c.words += words
+	}
+	return row
+}
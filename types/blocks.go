@@ -0,0 +1,77 @@
+package types
+
+import "math/big"
+
+// DefaultBinomialCacheWords is the size in machine words of the BinomialCache of one unrank, the default of every package.
+const DefaultBinomialCacheWords = 1 << 20
+
+/*
+Return the sum over u in [|0,t|] of C(t,u) 2^(shift u) column[r-u], for r-u >= low : the number of ways
+to end a block that may still take any subset of its t last candidates, each one in 2^shift ways, and to
+split the r elements left, column[m] counting the ways to split m elements. The binomial coefficients are
+read from binomials, which may be nil.
+*/
+func Tail(column []big.Int, t, r, low, shift int, binomials *BinomialCache) *big.Int {
+	res := new(big.Int)
+	l := min(t, r-low)
+	if l < 0 {
+		return res
+	}
+	row := binomials.Row(t, l)
+	tmp := new(big.Int)
+	for u := 0; u <= l; u++ {
+		tmp.Mul(&row[u], &column[r-u])
+		res.Add(res, tmp.Lsh(tmp, uint(shift*u)))
+	}
+	return res
+}
+
+// Search returns the smallest i in [lo, hi] with f(i) < limit, f being decreasing, hi if there is none.
+func Search(lo, hi int, limit *big.Int, f func(i int) *big.Int) int {
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if f(mid).Cmp(limit) < 0 {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo
+}
+
+// StirlingColumns returns the columns 0 to k of the Stirling triangle until the line n, columns[j][i] = S(i,j).
+func StirlingColumns(n, k int) [][]big.Int {
+	columns := make([][]big.Int, k+1)
+	for j := range columns {
+		columns[j] = make([]big.Int, n+1)
+	}
+	columns[0][0].SetInt64(1)
+	bj := new(big.Int)
+	for j := 1; j <= k; j++ {
+		bj.SetInt64(int64(j))
+		for i := j; i <= n; i++ {
+			columns[j][i].Mul(bj, &columns[j][i-1])
+			columns[j][i].Add(&columns[j][i], &columns[j-1][i-1])
+		}
+	}
+	return columns
+}
+
+/*
+PreviousColumn returns the column k-1 until the line n of a triangle T(t,k) = T(t-1,k-1) + factor*T(t-1,k)
+from its column k, T(t-1,k-1) = T(t,k) - factor*T(t-1,k), the line n being left at 0.
+The result is written in dst when it is long enough.
+*/
+func PreviousColumn(dst, column []big.Int, n int, factor int64) []big.Int {
+	if cap(dst) < n+1 {
+		dst = make([]big.Int, n+1)
+	}
+	dst = dst[:n+1]
+	f := big.NewInt(factor)
+	for i := 1; i <= n; i++ {
+		dst[i-1].Mul(f, &column[i-1])
+		dst[i-1].Sub(&column[i], &dst[i-1])
+	}
+	dst[n].SetInt64(0)
+	return dst
+}
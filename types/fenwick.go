@@ -0,0 +1,90 @@
+package types
+
+// fenwick counts the elements of [|1,n|] that are still present.
+type fenwick struct {
+	tree []int
+	// highest power of 2 <= n
+	step int
+}
+
+func newFenwick(n int) *fenwick {
+	f := &fenwick{tree: make([]int, n+1), step: 1}
+	for i := 1; i <= n; i++ {
+		f.tree[i]++
+		if j := i + i&-i; j <= n {
+			f.tree[j] += f.tree[i]
+		}
+	}
+	for f.step*2 <= n {
+		f.step *= 2
+	}
+	return f
+}
+
+func (f *fenwick) remove(x int) {
+	for ; x < len(f.tree); x += x & -x {
+		f.tree[x]--
+	}
+}
+
+// find returns the element of position i (from 0) among the present ones.
+func (f *fenwick) find(i int) int {
+	x := 0
+	for s := f.step; s > 0; s /= 2 {
+		if x+s < len(f.tree) && f.tree[x+s] <= i {
+			x += s
+			i -= f.tree[x]
+		}
+	}
+	return x + 1
+}
+
+//  Turn position codes into elements.
+/*
+Pos[b] lists the positions of the elements of the block b in the list of the elements of [|1,n|]
+that are not yet taken, each element being removed from the list as soon as it is read.
+Return the blocks with their elements, in O(n log n).
+*/
+func LexicographicPermutationUnrank(n int, Pos [][]int) [][]int {
+	return LexicographicPermutationUnrankInto(nil, n, Pos)
+}
+
+// LexicographicPermutationUnrankInto is LexicographicPermutationUnrank writing the blocks in those of dst, reused when they are large enough.
+func LexicographicPermutationUnrankInto(dst [][]int, n int, Pos [][]int) [][]int {
+	if cap(dst) < len(Pos) {
+		dst = append(dst[:cap(dst)], make([][]int, len(Pos)-cap(dst))...)
+	}
+	dst = dst[:len(Pos)]
+	f := newFenwick(n)
+	for b := range Pos {
+		if cap(dst[b]) < len(Pos[b]) {
+			dst[b] = make([]int, len(Pos[b]))
+		}
+		dst[b] = dst[b][:len(Pos[b])]
+		for j, i := range Pos[b] {
+			x := f.find(i)
+			f.remove(x)
+			dst[b][j] = x
+		}
+	}
+	return dst
+}
+
+// Remaining is the list of the elements of [|1,n|] not yet taken, in increasing order.
+type Remaining struct {
+	f *fenwick
+}
+
+func NewRemaining(n int) *Remaining {
+	return &Remaining{f: newFenwick(n)}
+}
+
+// Take removes the elements at the positions pos and returns them, each one being removed as soon as it is read, in O(len(pos) log n).
+func (r *Remaining) Take(pos []int) []int {
+	res := make([]int, len(pos))
+	for j, i := range pos {
+		res[j] = r.f.find(i)
+		r.f.remove(res[j])
+	}
+	return res
+}
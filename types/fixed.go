@@ -0,0 +1,194 @@
+package types
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// word is an unsigned machine integer, word64 or word128, whose operations wrap around.
+type word[T any] interface {
+	add(T) T
+	sub(T) T
+	mul(T) T
+	less(T) bool
+}
+
+type word64 uint64
+
+func (x word64) add(y word64) word64 { return x + y }
+func (x word64) sub(y word64) word64 { return x - y }
+func (x word64) mul(y word64) word64 { return x * y }
+func (x word64) less(y word64) bool  { return x < y }
+
+// word128 is hi 2^64 + lo.
+type word128 struct {
+	hi, lo uint64
+}
+
+func (x word128) add(y word128) word128 {
+	lo, carry := bits.Add64(x.lo, y.lo, 0)
+	return word128{x.hi + y.hi + carry, lo}
+}
+
+func (x word128) sub(y word128) word128 {
+	lo, borrow := bits.Sub64(x.lo, y.lo, 0)
+	return word128{x.hi - y.hi - borrow, lo}
+}
+
+func (x word128) mul(y word128) word128 {
+	hi, lo := bits.Mul64(x.lo, y.lo)
+	return word128{hi + x.hi*y.lo + x.lo*y.hi, lo}
+}
+
+func (x word128) less(y word128) bool {
+	return x.hi < y.hi || (x.hi == y.hi && x.lo < y.lo)
+}
+
+// FixedWidthUnranker unranks the set partitions of [|1,n|] into k blocks on 64 or 128 bits machine words.
+type FixedWidthUnranker struct {
+	n, k int
+	// in one of the two widths, S(j+i, j) = tri[j][i] for j <= k and i <= n-k,
+	// and C(m, u) = binomial[m][u] for u <= n-k modulo 2^64 or 2^128, exact when used
+	tri64, binomial64   [][]word64
+	tri128, binomial128 [][]word128
+}
+
+/*
+Return the unranker of the set partitions of [|1,n|] into k blocks on machine words, 64 bits ones
+when S(n,k) < 2^64, 128 bits ones when S(n,k) < 2^128, or nil when math/big is needed. All the numbers
+used by the unrank are at most S(n,k), the other ones are only known modulo the width.
+*/
+func NewFixedWidthUnranker(n, k int) *FixedWidthUnranker {
+	if k < 1 || k > n {
+		return nil
+	}
+	// quick rejection, S(n,k) >= k^(n-k)
+	if (n-k)*(bits.Len(uint(k))-1) >= 128 {
+		return nil
+	}
+	tri := make([][]word128, k+1)
+	for j := range tri {
+		tri[j] = make([]word128, n-k+1)
+	}
+	tri[0][0] = word128{0, 1}
+	for j := 1; j <= k; j++ {
+		tri[j][0] = word128{0, 1}
+		for i := 1; i <= n-k; i++ {
+			// S(j+i, j) = j S(j+i-1, j) + S(j+i-1, j-1), without overflow
+			hi, lo := bits.Mul64(tri[j][i-1].lo, uint64(j))
+			over, hi2 := bits.Mul64(tri[j][i-1].hi, uint64(j))
+			hi, carry := bits.Add64(hi, hi2, 0)
+			if over != 0 || carry != 0 {
+				return nil
+			}
+			lo, carry = bits.Add64(lo, tri[j-1][i].lo, 0)
+			hi, carry = bits.Add64(hi, tri[j-1][i].hi, carry)
+			if carry != 0 {
+				return nil
+			}
+			tri[j][i] = word128{hi, lo}
+		}
+	}
+	// Pascal's rule modulo 2^128
+	binomial := make([][]word128, n+1)
+	for m := range binomial {
+		binomial[m] = make([]word128, min(m, n-k)+1)
+		binomial[m][0] = word128{0, 1}
+		for u := 1; u < len(binomial[m]); u++ {
+			binomial[m][u] = binomial[m-1][u-1]
+			if u < m {
+				binomial[m][u] = binomial[m][u].add(binomial[m-1][u])
+			}
+		}
+	}
+	f := &FixedWidthUnranker{n: n, k: k}
+	if tri[k][n-k].hi != 0 {
+		f.tri128, f.binomial128 = tri, binomial
+		return f
+	}
+	f.tri64, f.binomial64 = lowWords(tri), lowWords(binomial)
+	return f
+}
+
+// lowWords returns the numbers of table modulo 2^64.
+func lowWords(table [][]word128) [][]word64 {
+	res := make([][]word64, len(table))
+	for i := range table {
+		res[i] = make([]word64, len(table[i]))
+		for j := range table[i] {
+			res[i][j] = word64(table[i][j].lo)
+		}
+	}
+	return res
+}
+
+// Width returns the number of bits of the machine words used, 64 or 128.
+func (f *FixedWidthUnranker) Width() int {
+	if f.tri64 != nil {
+		return 64
+	}
+	return 128
+}
+
+/*
+Return the blocks of the set partition of rank rank < S(n,k) in the order of parallelunranking.UnrankDicho,
+each element being given by its position in the list of the elements not yet taken, as expected by
+LexicographicPermutationUnrank.
+*/
+func (f *FixedWidthUnranker) UnrankPositions(rank *big.Int) [][]int {
+	lo := new(big.Int).And(rank, new(big.Int).SetUint64(^uint64(0))).Uint64()
+	if f.tri64 != nil {
+		return unrankWords(f.n, f.k, word64(lo), f.tri64, f.binomial64)
+	}
+	hi := new(big.Int).Rsh(rank, 64).Uint64()
+	return unrankWords(f.n, f.k, word128{hi, lo}, f.tri128, f.binomial128)
+}
+
+// unrankWords is UnrankPositions with the rank and the tables of FixedWidthUnranker in the width T.
+func unrankWords[T word[T]](n, k int, r T, tri, binomial [][]T) [][]int {
+	stirling := func(t, j int) T {
+		var zero T
+		if t < j {
+			return zero
+		}
+		return tri[j][t-j]
+	}
+	res := make([][]int, 0, k)
+	for ; k > 1; k-- {
+		// the block starts with the smallest element, R elements are left out of it, the c last ones
+		// are greater than its last element p, and the other blocks are kk
+		kk := k - 1
+		R, c, p := n-1, n-1, 0
+		// the number of ways to extend the block with a subset of the m last elements
+		tail := func(m int) T {
+			var sum T
+			for u := 0; u <= m && u <= R-kk; u++ {
+				sum = sum.add(binomial[m][u].mul(stirling(R-u, kk)))
+			}
+			return sum
+		}
+		block := []int{0}
+		for stop := stirling(R, kk); !r.less(stop); stop = stirling(R, kk) {
+			// the next element is the one with m-1 elements greater than it,
+			// m the smallest one such that tail(m) >= tail(c) - (r - stop)
+			total := tail(c)
+			target := total.sub(r.sub(stop))
+			lo, hi := 1, c
+			for lo < hi {
+				mid := (lo + hi) / 2
+				if tail(mid).less(target) {
+					lo = mid + 1
+				} else {
+					hi = mid
+				}
+			}
+			r = r.sub(stop).sub(total.sub(tail(lo)))
+			p += c - lo
+			block = append(block, p)
+			R, c = R-1, lo-1
+		}
+		res = append(res, block)
+		n = R
+	}
+	return append(res, make([]int, n))
+}
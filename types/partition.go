@@ -0,0 +1,232 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+)
+
+/*
+Partition is a set partition of [|1,n|] stored as a label array : the element x lies in the
+block Labels()[x-1]. The blocks are numbered from 0 in the order they were given, the
+lexicographic order of the library being the one of Canonical.
+*/
+type Partition struct {
+	labels []int
+	sizes  []int
+	// first structural error met while building the partition
+	err error
+}
+
+// NewPartition builds the Partition whose blocks are the given lists, as returned by UnrankDicho.
+func NewPartition(blocks [][]int) Partition {
+	n := 0
+	for _, block := range blocks {
+		for _, x := range block {
+			if x > n {
+				n = x
+			}
+		}
+	}
+	p := Partition{labels: make([]int, n), sizes: make([]int, len(blocks))}
+	for x := range p.labels {
+		p.labels[x] = -1
+	}
+	for b, block := range blocks {
+		for _, x := range block {
+			switch {
+			case x < 1:
+				p.setErr(fmt.Errorf("element %d out of range", x))
+			case p.labels[x-1] >= 0:
+				p.setErr(fmt.Errorf("element %d in blocks %d and %d", x, p.labels[x-1], b))
+			default:
+				p.labels[x-1] = b
+				p.sizes[b]++
+			}
+		}
+	}
+	return p
+}
+
+// NewPartitionFromLabels builds the Partition where the element x lies in the block labels[x-1].
+func NewPartitionFromLabels(labels []int) Partition {
+	p := Partition{labels: append([]int{}, labels...)}
+	for x, b := range p.labels {
+		if b < 0 {
+			p.setErr(fmt.Errorf("element %d has no block", x+1))
+			continue
+		}
+		for len(p.sizes) <= b {
+			p.sizes = append(p.sizes, 0)
+		}
+		p.sizes[b]++
+	}
+	return p
+}
+
+func (p *Partition) setErr(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+// Return the number of elements.
+func (p Partition) N() int {
+	return len(p.labels)
+}
+
+// Return the number of blocks.
+func (p Partition) K() int {
+	return len(p.sizes)
+}
+
+// Return a copy of the label array.
+func (p Partition) Labels() []int {
+	return append([]int{}, p.labels...)
+}
+
+// Return the blocks, each one sorted.
+func (p Partition) Blocks() [][]int {
+	res := make([][]int, len(p.sizes))
+	for b, s := range p.sizes {
+		res[b] = make([]int, 0, s)
+	}
+	for x, b := range p.labels {
+		if b >= 0 {
+			res[b] = append(res[b], x+1)
+		}
+	}
+	return res
+}
+
+// Validate checks that p is a partition of [|1,n|] into exactly k non-empty blocks.
+func (p Partition) Validate(n, k int) error {
+	if p.err != nil {
+		return p.err
+	}
+	if len(p.labels) != n {
+		return fmt.Errorf("partition of [1,%d] instead of [1,%d]", len(p.labels), n)
+	}
+	for x, b := range p.labels {
+		if b < 0 {
+			return fmt.Errorf("element %d has no block", x+1)
+		}
+	}
+	for b, s := range p.sizes {
+		if s == 0 {
+			return fmt.Errorf("block %d is empty", b)
+		}
+	}
+	if len(p.sizes) != k {
+		return fmt.Errorf("%d blocks instead of %d", len(p.sizes), k)
+	}
+	return nil
+}
+
+// Return the block holding x, -1 if there is none.
+func (p Partition) BlockOf(x int) int {
+	if x < 1 || x > len(p.labels) {
+		return -1
+	}
+	return p.labels[x-1]
+}
+
+// Refines reports whether every block of p is included in a block of other, both partitioning the same set.
+func (p Partition) Refines(other Partition) bool {
+	if len(p.labels) != len(other.labels) {
+		return false
+	}
+	image := make([]int, len(p.sizes))
+	for b := range image {
+		image[b] = -1
+	}
+	for x, b := range p.labels {
+		if b < 0 {
+			continue
+		}
+		if image[b] < 0 {
+			image[b] = other.labels[x]
+		} else if image[b] != other.labels[x] {
+			return false
+		}
+	}
+	return true
+}
+
+// Meet returns the coarsest partition refining p and other, in canonical order, both being valid partitions of the same set.
+func (p Partition) Meet(other Partition) Partition {
+	res := make([]int, len(p.labels))
+	// label[{b, c}] is the block of the meet made of the block b of p and the block c of other
+	label := make(map[[2]int]int)
+	for x, b := range p.labels {
+		pair := [2]int{b, other.labels[x]}
+		l, ok := label[pair]
+		if !ok {
+			// the blocks are numbered by increasing smallest element, the meet is already canonical
+			l = len(label)
+			label[pair] = l
+		}
+		res[x] = l
+	}
+	return NewPartitionFromLabels(res)
+}
+
+// Join returns the finest partition refined by p and other, in canonical order, both being valid partitions of the same set.
+func (p Partition) Join(other Partition) Partition {
+	n := len(p.labels)
+	parent := make([]int, n)
+	for x := range parent {
+		parent[x] = x
+	}
+	var find func(x int) int
+	find = func(x int) int {
+		for parent[x] != x {
+			parent[x] = parent[parent[x]]
+			x = parent[x]
+		}
+		return x
+	}
+	for _, q := range []Partition{p, other} {
+		head := make([]int, len(q.sizes))
+		labels := q.labels
+		for x, b := range labels {
+			if head[b] > 0 {
+				parent[find(x)] = find(head[b] - 1)
+			} else {
+				head[b] = x + 1
+			}
+		}
+	}
+	res := make([]int, n)
+	for x := range res {
+		res[x] = find(x)
+	}
+	return NewPartitionFromLabels(res).Canonical()
+}
+
+// Shape returns the sizes of the blocks in decreasing order.
+func (p Partition) Shape() []int {
+	res := append([]int{}, p.sizes...)
+	sort.Sort(sort.Reverse(sort.IntSlice(res)))
+	return res
+}
+
+// Canonical renumbers the blocks by increasing smallest element, the order of UnrankDicho.
+func (p Partition) Canonical() Partition {
+	relabel := make([]int, len(p.sizes))
+	for b := range relabel {
+		relabel[b] = -1
+	}
+	res := Partition{labels: make([]int, len(p.labels)), sizes: make([]int, 0, len(p.sizes)), err: p.err}
+	for x, b := range p.labels {
+		if b < 0 {
+			res.labels[x] = -1
+			continue
+		}
+		if relabel[b] < 0 {
+			relabel[b] = len(res.sizes)
+			res.sizes = append(res.sizes, p.sizes[b])
+		}
+		res.labels[x] = relabel[b]
+	}
+	return res
+}
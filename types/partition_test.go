@@ -0,0 +1,143 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+// allRGS returns the restricted growth strings of length n, in lexicographic order.
+func allRGS(n int) [][]int {
+	res := make([][]int, 0)
+	rgs := make([]int, n)
+	var grow func(x, m int)
+	grow = func(x, m int) {
+		if x == n {
+			res = append(res, append([]int{}, rgs...))
+			return
+		}
+		for a := 1; a <= m+1; a++ {
+			rgs[x] = a
+			grow(x+1, max(m, a))
+		}
+	}
+	grow(0, 0)
+	return res
+}
+
+func TestConversions(t *testing.T) {
+	for n := 1; n <= 6; n++ {
+		for _, rgs := range allRGS(n) {
+			blocks := RGSToBlocks(rgs)
+			if got := BlocksToRGS(blocks); fmt.Sprint(got) != fmt.Sprint(rgs) {
+				t.Fatalf("BlocksToRGS(%v) = %v, want %v", blocks, got, rgs)
+			}
+			// the same partition with its blocks reversed
+			reversed := make([][]int, len(blocks))
+			for b, block := range blocks {
+				reversed[len(blocks)-1-b] = block
+			}
+			p := NewPartition(reversed)
+			if err := p.Validate(n, len(blocks)); err != nil {
+				t.Fatalf("Validate(%v) = %v", reversed, err)
+			}
+			if got := p.Canonical().Blocks(); fmt.Sprint(got) != fmt.Sprint(blocks) {
+				t.Fatalf("Canonical(%v) = %v, want %v", reversed, got, blocks)
+			}
+			if got := LabelsToRGS(p.Labels()); fmt.Sprint(got) != fmt.Sprint(rgs) {
+				t.Fatalf("LabelsToRGS(%v) = %v, want %v", p.Labels(), got, rgs)
+			}
+			for x := 1; x <= n; x++ {
+				if got := p.BlockOf(x); got != len(blocks)-rgs[x-1] {
+					t.Fatalf("%v.BlockOf(%d) = %d, want %d", reversed, x, got, len(blocks)-rgs[x-1])
+				}
+			}
+			shape := make([]int, len(blocks))
+			for b, block := range blocks {
+				shape[b] = len(block)
+			}
+			sort.Sort(sort.Reverse(sort.IntSlice(shape)))
+			if got := p.Shape(); fmt.Sprint(got) != fmt.Sprint(shape) {
+				t.Fatalf("Shape(%v) = %v, want %v", reversed, got, shape)
+			}
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, c := range []struct {
+		blocks [][]int
+		n, k   int
+		ok     bool
+	}{
+		{[][]int{{1, 3}, {2}}, 3, 2, true},
+		{[][]int{{1, 3}, {2}}, 4, 2, false},
+		{[][]int{{1, 3}, {2}}, 3, 3, false},
+		{[][]int{{1, 3}, {2, 3}}, 3, 2, false},
+		{[][]int{{1, 4}, {2}}, 4, 2, false},
+		{[][]int{{0, 1}, {2}}, 2, 2, false},
+		{[][]int{{1, 2}, {}}, 2, 2, false},
+	} {
+		if err := NewPartition(c.blocks).Validate(c.n, c.k); (err == nil) != c.ok {
+			t.Errorf("Validate(%v, %d, %d) = %v, want ok %v", c.blocks, c.n, c.k, err, c.ok)
+		}
+	}
+}
+
+func TestLattice(t *testing.T) {
+	for n := 1; n <= 5; n++ {
+		all := allRGS(n)
+		for _, a := range all {
+			p := NewPartitionFromRGS(a)
+			for _, b := range all {
+				q := NewPartitionFromRGS(b)
+				refines := true
+				for x := range a {
+					for y := range a {
+						if a[x] == a[y] && b[x] != b[y] {
+							refines = false
+						}
+					}
+				}
+				if p.Refines(q) != refines {
+					t.Fatalf("%v.Refines(%v) = %v, want %v", a, b, !refines, refines)
+				}
+				// x and y share a block of the meet when they share a block of both
+				meet := p.Meet(q)
+				// the join is the partition with the most blocks refined by both
+				var join []int
+				for _, c := range all {
+					r := NewPartitionFromRGS(c)
+					if p.Refines(r) && q.Refines(r) && (join == nil || r.K() > NewPartitionFromRGS(join).K()) {
+						join = c
+					}
+				}
+				for x := range a {
+					for y := range a {
+						if same := meet.BlockOf(x+1) == meet.BlockOf(y+1); same != (a[x] == a[y] && b[x] == b[y]) {
+							t.Fatalf("%v meet %v = %v", a, b, meet.Blocks())
+						}
+					}
+				}
+				if got := meet.RGS(); fmt.Sprint(meet.Labels()) != fmt.Sprint(NewPartitionFromRGS(got).Labels()) {
+					t.Fatalf("%v meet %v = %v is not canonical", a, b, meet.Labels())
+				}
+				if got := p.Join(q).RGS(); fmt.Sprint(got) != fmt.Sprint(join) {
+					t.Fatalf("%v join %v = %v, want %v", a, b, got, join)
+				}
+			}
+		}
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	for _, pos := range [][][]int{{{0, 0, 1}, {0}, {0, 0}}, {{0}, {0}, {0}, {0}, {0}, {0}}, {{0, 4}, {2, 0}, {0, 0}}} {
+		want := LexicographicPermutationUnrank(6, pos)
+		r := NewRemaining(6)
+		for b := range pos {
+			if got := r.Take(pos[b]); fmt.Sprint(got) != fmt.Sprint(want[b]) {
+				t.Fatalf("Take(%v) = %v, want %v", pos[b], got, want[b])
+			}
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package types
+
+/*
+A restricted growth string (RGS) of a set partition of [|1,n|] is the word a_1...a_n where a_x is
+the number of the block holding x, the blocks being numbered from 1 by increasing smallest element.
+So a_1 = 1 and a_x <= 1 + max(a_1, ..., a_{x-1}).
+*/
+
+// Return the restricted growth string of p.
+func (p Partition) RGS() []int {
+	res := p.Canonical().labels
+	for x := range res {
+		res[x]++
+	}
+	return res
+}
+
+// NewPartitionFromRGS builds the Partition of the restricted growth string rgs.
+func NewPartitionFromRGS(rgs []int) Partition {
+	labels := make([]int, len(rgs))
+	for x, a := range rgs {
+		labels[x] = a - 1
+	}
+	return NewPartitionFromLabels(labels)
+}
+
+// BlocksToRGS returns the restricted growth string of a set partition of [|1,n|] given by its blocks.
+func BlocksToRGS(blocks [][]int) []int {
+	return NewPartition(blocks).RGS()
+}
+
+// RGSToBlocks returns the blocks of the restricted growth string rgs, sorted like UnrankDicho does.
+func RGSToBlocks(rgs []int) [][]int {
+	return NewPartitionFromRGS(rgs).Canonical().Blocks()
+}
+
+// LabelsToRGS returns the restricted growth string of a label vector, labels[x-1] being the block of x.
+func LabelsToRGS(labels []int) []int {
+	return NewPartitionFromLabels(labels).RGS()
+}